@@ -0,0 +1,229 @@
+/*
+Package animation plays sequences of frames of sprite sheets.
+
+A Sequence lists the frames of a sheet, which can be taken from a grid with
+GridFrames, from a texture atlas with RectFrames, or from a sheet exported
+by Aseprite with LoadAseprite. An Animation plays a sequence, taking the
+time from GetTicks or from its own clock:
+
+	walk := animation.NewSequence("walk", sheet,
+		animation.GridFrames(sheet, 32, 32, 0, 8, 100), animation.Loop)
+	a := animation.NewAnimation(walk)
+	a.OnFinish = func(a *animation.Animation) { ... }
+	for {
+		a.Draw(screen, x, y)
+		screen.Flip()
+	}
+*/
+package animation
+
+import "sdl"
+
+// Plays a sequence. The animation advances when Update or Draw is called,
+// by the time since the previous call; the callbacks are called from there.
+type Animation struct {
+	// Returns the current time in milliseconds. If nil, sdl.GetTicks is
+	// used. Changing it while playing may skip or repeat frames.
+	Clock func() uint32
+
+	// Called when a Loop or PingPong sequence starts over
+	OnLoop func(a *Animation)
+
+	// Called when the animation has finished, after the last frame of a
+	// Once sequence or the last repetition of a sequence
+	OnFinish func(a *Animation)
+
+	sequence *Sequence
+	index    int
+	forward  bool
+	elapsed  uint32 // In the current frame
+	last     uint32 // The time of the previous update
+	cycles   int
+	paused   bool
+	finished bool
+	started  uint64 // Incremented by Play
+}
+
+// Creates an animation playing the sequence, starting now.
+func NewAnimation(s *Sequence) *Animation {
+	a := new(Animation)
+	a.Play(s)
+	return a
+}
+
+func (a *Animation) now() uint32 {
+	if a.Clock != nil {
+		return a.Clock()
+	}
+	return sdl.GetTicks()
+}
+
+// Starts playing the sequence from its first frame, even if it is already
+// playing. A paused animation is resumed.
+func (a *Animation) Play(s *Sequence) {
+	a.sequence = s
+	a.index = 0
+	a.forward = true
+	a.elapsed = 0
+	a.last = a.now()
+	a.cycles = 0
+	a.paused = false
+	a.finished = false
+	a.started++
+}
+
+// Plays the current sequence again from the first frame.
+func (a *Animation) Restart() {
+	a.Play(a.sequence)
+}
+
+// Returns the sequence being played.
+func (a *Animation) Sequence() *Sequence {
+	return a.sequence
+}
+
+// Stops the animation at the current frame until Resume is called.
+func (a *Animation) Pause() {
+	if !a.paused {
+		a.Update()
+		a.paused = true
+	}
+}
+
+// Continues a paused animation. The time it was paused is skipped.
+func (a *Animation) Resume() {
+	if a.paused {
+		a.paused = false
+		a.last = a.now()
+	}
+}
+
+// Returns whether the animation is paused.
+func (a *Animation) Paused() bool {
+	return a.paused
+}
+
+// Returns whether the animation has finished. Only Once sequences and
+// sequences with a Repeat count finish.
+func (a *Animation) Finished() bool {
+	return a.finished
+}
+
+// Returns the index of the current frame in the sequence.
+func (a *Animation) Index() int {
+	return a.index
+}
+
+// Returns the current frame, or nil if the sequence has no frames.
+func (a *Animation) Frame() *Frame {
+	if (a.sequence == nil) || (a.index >= len(a.sequence.Frames)) {
+		return nil
+	}
+	return &a.sequence.Frames[a.index]
+}
+
+// Advances the animation to the current time of the clock, calling OnLoop
+// and OnFinish as sequences end.
+func (a *Animation) Update() {
+	now := a.now()
+	dt := now - a.last
+	a.last = now
+
+	s := a.sequence
+	if a.paused || a.finished || (s == nil) || (s.Duration() == 0) {
+		return
+	}
+
+	started := a.started
+	a.elapsed += dt
+	for !a.finished && (a.elapsed >= s.Frames[a.index].Duration) {
+		a.elapsed -= s.Frames[a.index].Duration
+		a.advance()
+
+		// The callbacks may have started another sequence
+		if a.started != started {
+			return
+		}
+	}
+}
+
+// Moves to the next frame of the sequence.
+func (a *Animation) advance() {
+	s := a.sequence
+	n := len(s.Frames)
+
+	switch s.Mode {
+	case Once:
+		if a.index == n-1 {
+			a.finish()
+			return
+		}
+		a.index++
+
+	case PingPong:
+		if n == 1 {
+			a.cycle()
+			return
+		}
+		if (a.forward && (a.index == n-1)) || (!a.forward && (a.index == 0)) {
+			a.forward = !a.forward
+		}
+		if a.forward {
+			a.index++
+		} else {
+			a.index--
+			if a.index == 0 {
+				a.cycle()
+			}
+		}
+
+	default:
+		if a.index < n-1 {
+			a.index++
+			return
+		}
+		a.cycle()
+		if !a.finished {
+			a.index = 0
+		}
+	}
+}
+
+// Counts a repetition of the sequence, which finishes the animation if it
+// was the last one.
+func (a *Animation) cycle() {
+	a.cycles++
+	if (a.sequence.Repeat > 0) && (a.cycles >= a.sequence.Repeat) {
+		a.finish()
+		return
+	}
+	if a.OnLoop != nil {
+		a.OnLoop(a)
+	}
+}
+
+func (a *Animation) finish() {
+	a.finished = true
+	a.elapsed = 0
+	if a.OnFinish != nil {
+		a.OnFinish(a)
+	}
+}
+
+// Updates the animation and draws its current frame onto dst at x, y.
+// Returns 0 if successful or -1 on error, including when the sequence has
+// no sheet.
+func (a *Animation) Draw(dst *sdl.Surface, x, y int) int {
+	a.Update()
+
+	f := a.Frame()
+	if f == nil {
+		return 0
+	}
+	if a.sequence.Sheet == nil {
+		sdl.SetError("animation: Draw: the sequence has no sheet")
+		return -1
+	}
+	src := f.Source
+	return dst.Blit(&sdl.Rect{X: int16(x + f.OffsetX), Y: int16(y + f.OffsetY)}, a.sequence.Sheet, &src)
+}
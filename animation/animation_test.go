@@ -0,0 +1,144 @@
+package animation
+
+import (
+	"reflect"
+	"sdl"
+	"testing"
+)
+
+// An animation of a sequence of n frames of 10 milliseconds, playing on
+// a clock which is advanced by the returned function.
+func newTestAnimation(n int, mode Mode, repeat int) (a *Animation, tick func(dt uint32)) {
+	var now uint32
+	a = &Animation{Clock: func() uint32 { return now }}
+
+	frames := make([]Frame, n)
+	for i := range frames {
+		frames[i].Duration = 10
+	}
+	s := NewSequence("test", nil, frames, mode)
+	s.Repeat = repeat
+	a.Play(s)
+
+	return a, func(dt uint32) {
+		now += dt
+		a.Update()
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		frames   int
+		mode     Mode
+		repeat   int
+		steps    []uint32 // Milliseconds between the updates
+		indices  []int    // After each update
+		loops    int
+		finishes int
+	}{
+		{"loop", 3, Loop, 0, []uint32{10, 10, 10, 10}, []int{1, 2, 0, 1}, 1, 0},
+		{"loop within a frame", 3, Loop, 0, []uint32{5, 4, 1}, []int{0, 0, 1}, 0, 0},
+		{"loop skipping frames", 3, Loop, 0, []uint32{25, 25}, []int{2, 2}, 1, 0},
+		{"loop repeated", 3, Loop, 2, []uint32{10, 10, 10, 10, 10, 10, 10}, []int{1, 2, 0, 1, 2, 2, 2}, 1, 1},
+		{"ping-pong", 3, PingPong, 0, []uint32{10, 10, 10, 10, 10, 10}, []int{1, 2, 1, 0, 1, 2}, 1, 0},
+		{"ping-pong repeated", 3, PingPong, 1, []uint32{10, 10, 10, 10, 10}, []int{1, 2, 1, 0, 0}, 0, 1},
+		{"ping-pong of one frame", 1, PingPong, 0, []uint32{10, 10}, []int{0, 0}, 2, 0},
+		{"once", 3, Once, 0, []uint32{10, 10, 10, 10}, []int{1, 2, 2, 2}, 0, 1},
+		{"once at once", 3, Once, 0, []uint32{100}, []int{2}, 0, 1},
+	}
+
+	for _, test := range tests {
+		a, tick := newTestAnimation(test.frames, test.mode, test.repeat)
+		loops, finishes := 0, 0
+		a.OnLoop = func(*Animation) { loops++ }
+		a.OnFinish = func(*Animation) { finishes++ }
+
+		var indices []int
+		for _, dt := range test.steps {
+			tick(dt)
+			indices = append(indices, a.Index())
+		}
+
+		if !reflect.DeepEqual(indices, test.indices) {
+			t.Errorf("%s: got frames %v, want %v", test.name, indices, test.indices)
+		}
+		if (loops != test.loops) || (finishes != test.finishes) {
+			t.Errorf("%s: got %d loops, %d finishes, want %d, %d", test.name, loops, finishes, test.loops, test.finishes)
+		}
+		if a.Finished() != (test.finishes > 0) {
+			t.Errorf("%s: Finished = %v", test.name, a.Finished())
+		}
+	}
+}
+
+func TestUpdateCallbacksRestart(t *testing.T) {
+	// OnLoop switches to another sequence, the rest of the time is dropped
+	a, tick := newTestAnimation(3, Loop, 0)
+	next := NewSequence("next", nil, []Frame{{Duration: 10}, {Duration: 10}}, Once)
+	a.OnLoop = func(a *Animation) { a.Play(next) }
+
+	tick(35)
+	if (a.Sequence() != next) || (a.Index() != 0) {
+		t.Errorf("after OnLoop: got sequence %q at frame %d, want %q at 0", a.Sequence().Name, a.Index(), next.Name)
+	}
+	tick(10)
+	if a.Index() != 1 {
+		t.Errorf("after OnLoop: got frame %d, want 1", a.Index())
+	}
+
+	// OnFinish plays the sequence again
+	a, tick = newTestAnimation(2, Once, 0)
+	finishes := 0
+	a.OnFinish = func(a *Animation) {
+		finishes++
+		a.Restart()
+	}
+
+	tick(20)
+	if a.Finished() || (a.Index() != 0) || (finishes != 1) {
+		t.Errorf("after OnFinish: got frame %d, finished %v, %d finishes, want 0, false, 1", a.Index(), a.Finished(), finishes)
+	}
+	tick(10)
+	if a.Index() != 1 {
+		t.Errorf("after OnFinish: got frame %d, want 1", a.Index())
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	a, tick := newTestAnimation(3, Loop, 0)
+
+	tick(5)
+	a.Pause()
+	if !a.Paused() {
+		t.Fatal("Paused = false after Pause")
+	}
+	tick(100)
+	if a.Index() != 0 {
+		t.Errorf("paused: got frame %d, want 0", a.Index())
+	}
+
+	// The 5 milliseconds before the pause still count
+	a.Resume()
+	if a.Paused() {
+		t.Error("Paused = true after Resume")
+	}
+	tick(5)
+	if a.Index() != 1 {
+		t.Errorf("resumed: got frame %d, want 1", a.Index())
+	}
+
+	// Play resumes a paused animation
+	a.Pause()
+	a.Restart()
+	if a.Paused() || (a.Index() != 0) {
+		t.Errorf("restarted: got frame %d, paused %v, want 0, false", a.Index(), a.Paused())
+	}
+}
+
+func TestDrawWithoutSheet(t *testing.T) {
+	a, _ := newTestAnimation(3, Loop, 0)
+	if a.Draw(&sdl.Surface{}, 0, 0) != -1 {
+		t.Error("Draw without a sheet did not fail")
+	}
+}
@@ -0,0 +1,172 @@
+package animation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sdl"
+	"strconv"
+)
+
+// The frames and sequences of a sprite sheet exported by Aseprite.
+type Atlas struct {
+	Sheet     *sdl.Surface
+	Frames    []Frame              // All frames, in the order of the export
+	Sequences map[string]*Sequence // By tag name; if there are no tags, one sequence named "" with all frames
+}
+
+type asepriteFile struct {
+	Frames json.RawMessage `json:"frames"` // An array, or an object by file name
+	Meta   struct {
+		Image     string `json:"image"`
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+			Repeat    string `json:"repeat"`
+		} `json:"frameTags"`
+	} `json:"meta"`
+}
+
+type asepriteFrame struct {
+	Frame struct {
+		X, Y, W, H int
+	} `json:"frame"`
+	Rotated          bool `json:"rotated"`
+	SpriteSourceSize struct {
+		X, Y int
+	} `json:"spriteSourceSize"`
+	Duration uint32 `json:"duration"`
+}
+
+// Loads a sprite sheet exported by Aseprite as a JSON file and the image
+// referenced by it, which is looked up relative to the directory of the
+// file. Both the array and the hash format of the frames are supported;
+// frames rotated in the sheet are not.
+func LoadAseprite(file string) (*Atlas, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadAseprite(f, filepath.Dir(file))
+}
+
+// Reads a sprite sheet exported by Aseprite as JSON and loads the image
+// referenced by it, which is looked up relative to dir.
+func ReadAseprite(r io.Reader, dir string) (*Atlas, error) {
+	var af asepriteFile
+	if err := json.NewDecoder(r).Decode(&af); err != nil {
+		return nil, err
+	}
+
+	frames, err := asepriteFrames(af.Frames)
+	if err != nil {
+		return nil, err
+	}
+
+	atlas := &Atlas{Sequences: make(map[string]*Sequence)}
+	for _, af := range frames {
+		if af.Rotated {
+			return nil, errors.New("animation: rotated frames are not supported")
+		}
+		atlas.Frames = append(atlas.Frames, Frame{
+			Source:   sdl.Rect{X: int16(af.Frame.X), Y: int16(af.Frame.Y), W: uint16(af.Frame.W), H: uint16(af.Frame.H)},
+			OffsetX:  af.SpriteSourceSize.X,
+			OffsetY:  af.SpriteSourceSize.Y,
+			Duration: af.Duration,
+		})
+	}
+
+	for _, tag := range af.Meta.FrameTags {
+		if (tag.From < 0) || (tag.To >= len(atlas.Frames)) || (tag.From > tag.To) {
+			return nil, errors.New("animation: tag \"" + tag.Name + "\" refers to missing frames")
+		}
+
+		frames := make([]Frame, tag.To-tag.From+1)
+		copy(frames, atlas.Frames[tag.From:tag.To+1])
+		mode := Loop
+		switch tag.Direction {
+		case "reverse":
+			reverse(frames)
+		case "pingpong":
+			mode = PingPong
+		case "pingpong_reverse":
+			reverse(frames)
+			mode = PingPong
+		}
+
+		s := &Sequence{Name: tag.Name, Frames: frames, Mode: mode}
+		if tag.Repeat != "" {
+			if s.Repeat, err = strconv.Atoi(tag.Repeat); err != nil {
+				return nil, errors.New("animation: invalid repeat count of tag \"" + tag.Name + "\"")
+			}
+		}
+		atlas.Sequences[tag.Name] = s
+	}
+	if len(af.Meta.FrameTags) == 0 {
+		atlas.Sequences[""] = &Sequence{Frames: atlas.Frames, Mode: Loop}
+	}
+
+	image := af.Meta.Image
+	if !filepath.IsAbs(image) {
+		image = filepath.Join(dir, image)
+	}
+	if atlas.Sheet = sdl.Load(image); atlas.Sheet == nil {
+		return nil, errors.New("animation: " + af.Meta.Image + ": " + sdl.GetError())
+	}
+	for _, s := range atlas.Sequences {
+		s.Sheet = atlas.Sheet
+	}
+
+	return atlas, nil
+}
+
+// Decodes the frames of an export in the array format, or in the hash
+// format keeping the order of the file.
+func asepriteFrames(data json.RawMessage) ([]asepriteFrame, error) {
+	var frames []asepriteFrame
+
+	data = bytes.TrimSpace(data)
+	if (len(data) > 0) && (data[0] == '[') {
+		err := json.Unmarshal(data, &frames)
+		return frames, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	if t, err := d.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("animation: invalid frames")
+	}
+	for d.More() {
+		// The file name
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+		var f asepriteFrame
+		if err := d.Decode(&f); err != nil {
+			return nil, err
+		}
+		frames = append(frames, f)
+	}
+	return frames, nil
+}
+
+func reverse(frames []Frame) {
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+}
+
+// Frees the sheet of the atlas.
+func (a *Atlas) Free() {
+	if a.Sheet != nil {
+		a.Sheet.Free()
+	}
+}
@@ -0,0 +1,92 @@
+package animation
+
+import "sdl"
+
+// How an animation continues after its last frame.
+type Mode int
+
+const (
+	// Starts over at the first frame.
+	Loop Mode = iota
+
+	// Plays the frames backwards to the first frame, then forwards again.
+	PingPong
+
+	// Stops at the last frame and finishes.
+	Once
+)
+
+// One frame of a sequence.
+type Frame struct {
+	Source           sdl.Rect // The area of the sheet
+	OffsetX, OffsetY int      // Added to the drawing position, for example for trimmed frames
+	Duration         uint32   // In milliseconds
+}
+
+// Frames of a sheet which are played as an animation.
+type Sequence struct {
+	Name   string
+	Sheet  *sdl.Surface
+	Frames []Frame
+	Mode   Mode
+
+	// For Loop and PingPong, the number of times the sequence is played
+	// before the animation finishes; 0 repeats it forever. A ping-pong
+	// sequence is played once when it is back at the first frame.
+	Repeat int
+}
+
+// Creates a sequence of frames of the sheet.
+func NewSequence(name string, sheet *sdl.Surface, frames []Frame, mode Mode) *Sequence {
+	return &Sequence{
+		Name:   name,
+		Sheet:  sheet,
+		Frames: frames,
+		Mode:   mode,
+	}
+}
+
+// Returns the duration of playing all frames once, in milliseconds.
+func (s *Sequence) Duration() uint32 {
+	var d uint32
+	for _, f := range s.Frames {
+		d += f.Duration
+	}
+	return d
+}
+
+// Returns frames for the cells of a sheet divided into a grid of cells of
+// size w, h. The cells are numbered from left to right and top to bottom,
+// starting at 0; count cells are returned starting at the cell first. If
+// count is 0 or less, all cells from first to the end of the sheet are
+// returned. Each frame lasts duration milliseconds.
+func GridFrames(sheet *sdl.Surface, w, h, first, count int, duration uint32) []Frame {
+	if (w <= 0) || (h <= 0) || (first < 0) {
+		return nil
+	}
+
+	columns, rows := int(sheet.W)/w, int(sheet.H)/h
+	if (count <= 0) || (first+count > columns*rows) {
+		count = columns*rows - first
+	}
+
+	var frames []Frame
+	for i := first; i < first+count; i++ {
+		x, y := (i%columns)*w, (i/columns)*h
+		frames = append(frames, Frame{
+			Source:   sdl.Rect{X: int16(x), Y: int16(y), W: uint16(w), H: uint16(h)},
+			Duration: duration,
+		})
+	}
+	return frames
+}
+
+// Returns frames for areas of a sheet, for example of a texture atlas.
+// Each frame lasts duration milliseconds.
+func RectFrames(rects []sdl.Rect, duration uint32) []Frame {
+	frames := make([]Frame, len(rects))
+	for i, r := range rects {
+		frames[i] = Frame{Source: r, Duration: duration}
+	}
+	return frames
+}
@@ -49,26 +49,6 @@ const (
 	LOGPAL       = C.SDL_LOGPAL
 	PHYSPAL      = C.SDL_PHYSPAL
 
-	// More setvideo flags: GLattr enumeration
-
-	GL_RED_SIZE           = C.SDL_GL_RED_SIZE
-	GL_GREEN_SIZE         = C.SDL_GL_GREEN_SIZE
-	GL_BLUE_SIZE          = C.SDL_GL_BLUE_SIZE
-	GL_ALPHA_SIZE         = C.SDL_GL_ALPHA_SIZE
-	GL_BUFFER_SIZE        = C.SDL_GL_BUFFER_SIZE
-	GL_DOUBLEBUFFER       = C.SDL_GL_DOUBLEBUFFER
-	GL_DEPTH_SIZE         = C.SDL_GL_DEPTH_SIZE
-	GL_STENCIL_SIZE       = C.SDL_GL_STENCIL_SIZE
-	GL_ACCUM_RED_SIZE     = C.SDL_GL_ACCUM_RED_SIZE
-	GL_ACCUM_GREEN_SIZE   = C.SDL_GL_ACCUM_GREEN_SIZE
-	GL_ACCUM_BLUE_SIZE    = C.SDL_GL_ACCUM_BLUE_SIZE
-	GL_ACCUM_ALPHA_SIZE   = C.SDL_GL_ACCUM_ALPHA_SIZE
-	GL_STEREO             = C.SDL_GL_STEREO
-	GL_MULTISAMPLEBUFFERS = C.SDL_GL_MULTISAMPLEBUFFERS
-	GL_MULTISAMPLESAMPLES = C.SDL_GL_MULTISAMPLESAMPLES
-	GL_ACCELERATED_VISUAL = C.SDL_GL_ACCELERATED_VISUAL
-	GL_SWAP_CONTROL       = C.SDL_GL_SWAP_CONTROL
-
 	// event types
 
 	NOEVENT         = C.SDL_NOEVENT
@@ -415,3 +395,26 @@ const (
 	BUTTON_X1MASK        = 1 << (BUTTON_X1 - 1)
 	BUTTON_X2MASK        = 1 << (BUTTON_X2 - 1)
 )
+
+// OpenGL attributes for GL_SetAttribute and GL_GetAttribute
+type GLAttr int
+
+const (
+	GL_RED_SIZE           GLAttr = C.SDL_GL_RED_SIZE
+	GL_GREEN_SIZE         GLAttr = C.SDL_GL_GREEN_SIZE
+	GL_BLUE_SIZE          GLAttr = C.SDL_GL_BLUE_SIZE
+	GL_ALPHA_SIZE         GLAttr = C.SDL_GL_ALPHA_SIZE
+	GL_BUFFER_SIZE        GLAttr = C.SDL_GL_BUFFER_SIZE
+	GL_DOUBLEBUFFER       GLAttr = C.SDL_GL_DOUBLEBUFFER
+	GL_DEPTH_SIZE         GLAttr = C.SDL_GL_DEPTH_SIZE
+	GL_STENCIL_SIZE       GLAttr = C.SDL_GL_STENCIL_SIZE
+	GL_ACCUM_RED_SIZE     GLAttr = C.SDL_GL_ACCUM_RED_SIZE
+	GL_ACCUM_GREEN_SIZE   GLAttr = C.SDL_GL_ACCUM_GREEN_SIZE
+	GL_ACCUM_BLUE_SIZE    GLAttr = C.SDL_GL_ACCUM_BLUE_SIZE
+	GL_ACCUM_ALPHA_SIZE   GLAttr = C.SDL_GL_ACCUM_ALPHA_SIZE
+	GL_STEREO             GLAttr = C.SDL_GL_STEREO
+	GL_MULTISAMPLEBUFFERS GLAttr = C.SDL_GL_MULTISAMPLEBUFFERS
+	GL_MULTISAMPLESAMPLES GLAttr = C.SDL_GL_MULTISAMPLESAMPLES
+	GL_ACCELERATED_VISUAL GLAttr = C.SDL_GL_ACCELERATED_VISUAL
+	GL_SWAP_CONTROL       GLAttr = C.SDL_GL_SWAP_CONTROL
+)
@@ -0,0 +1,178 @@
+package sdl
+
+import "sync"
+
+// Records the regions of the video surface changed by Blit and FillRect,
+// and updates only those regions of the display:
+//
+//	tracker := sdl.NewDirtyTracker(screen)
+//	for {
+//		screen.Blit(...) // Recorded automatically
+//		tracker.Present()
+//	}
+//
+// Changes made by other means, such as writing pixels directly, must be
+// recorded with Add. Nearby regions are merged before they are updated,
+// and the whole display is updated with Flip when most of it changed.
+type DirtyTracker struct {
+	// The cost of updating one more rectangle, in pixels. Two regions are
+	// merged if the area of their union is at most the sum of their areas
+	// plus this cost.
+	RectCost int
+
+	// The maximum number of rectangles passed to UpdateRects. More changed
+	// regions are updated with Flip.
+	MaxRects int
+
+	// The fraction of the area of the screen above which the whole screen
+	// is updated with Flip.
+	FlipRatio float64
+
+	screen   *Surface
+	mutex    sync.Mutex
+	rects    []Rect // Changed regions, merged by Present
+	full     bool   // Whether the whole screen is to be updated
+	recorded int    // The number of regions recorded since Present
+	stats    DirtyStats
+}
+
+// Statistics about one call to Present.
+type DirtyStats struct {
+	Recorded int  // The number of regions recorded since the previous frame
+	Rects    int  // The number of rectangles passed to UpdateRects, 0 if Flipped
+	Pixels   int  // The number of pixels updated
+	Flipped  bool // Whether the whole screen was updated with Flip
+}
+
+// Creates a tracker for the video surface and attaches it, so that Blit
+// and FillRect on the surface record the regions they change. A new video
+// surface returned by SetVideoMode needs a new tracker.
+func NewDirtyTracker(screen *Surface) *DirtyTracker {
+	t := &DirtyTracker{
+		RectCost:  1024,
+		MaxRects:  64,
+		FlipRatio: 0.6,
+		screen:    screen,
+	}
+
+	screen.mutex.Lock()
+	screen.dirty = t
+	screen.mutex.Unlock()
+
+	return t
+}
+
+// Detaches the tracker from the video surface, which stops recording.
+func (t *DirtyTracker) Detach() {
+	t.screen.mutex.Lock()
+	if t.screen.dirty == t {
+		t.screen.dirty = nil
+	}
+	t.screen.mutex.Unlock()
+}
+
+func area(r Rect) int {
+	return int(r.W) * int(r.H)
+}
+
+// Records a changed region. Parts outside of the screen are ignored. The
+// regions are only merged by Present, so recording is cheap.
+func (t *DirtyTracker) Add(r Rect) {
+	// Reads W and H directly, since Blit calls this while the screen is locked
+	r = r.Intersect(Rect{0, 0, uint16(t.screen.W), uint16(t.screen.H)})
+	if r.Empty() {
+		return
+	}
+
+	t.mutex.Lock()
+	t.recorded++
+	if !t.full {
+		t.rects = append(t.rects, r)
+	}
+	t.mutex.Unlock()
+}
+
+// Merges each region with every other one for which this is cheaper than
+// updating both, until no more regions can be merged.
+func mergeRects(rects []Rect, cost int) []Rect {
+	var merged []Rect
+	for _, r := range rects {
+		for again := true; again; {
+			again = false
+			for i, m := range merged {
+				u, _ := r.Union(m) // Both are inside the screen
+				if area(u) <= area(r)+area(m)+cost {
+					r = u
+					merged = append(merged[:i], merged[i+1:]...)
+					again = true
+					break
+				}
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Records that the whole screen changed.
+func (t *DirtyTracker) AddAll() {
+	t.mutex.Lock()
+	t.full = true
+	t.recorded++
+	t.rects = nil
+	t.mutex.Unlock()
+}
+
+// Updates the regions recorded since the previous call on the display,
+// and starts recording the next frame. Nothing is done if nothing was
+// recorded. Double-buffered screens (DOUBLEBUF) are always updated with
+// Flip. Returns 0 if successful or -1 if there was an error.
+func (t *DirtyTracker) Present() int {
+	t.mutex.Lock()
+	rects, full, recorded := t.rects, t.full, t.recorded
+	t.rects, t.full, t.recorded = nil, false, 0
+	t.mutex.Unlock()
+
+	stats := DirtyStats{Recorded: recorded}
+	if recorded == 0 {
+		// Nothing to update; flipping a DOUBLEBUF screen would show an old frame
+		t.mutex.Lock()
+		t.stats = stats
+		t.mutex.Unlock()
+		return 0
+	}
+
+	screen := t.screen
+	screenArea := int(screen.W) * int(screen.H)
+
+	rects = mergeRects(rects, t.RectCost)
+
+	for _, r := range rects {
+		stats.Pixels += area(r)
+	}
+
+	status := 0
+	if (screen.Flags&DOUBLEBUF != 0) || full || (len(rects) > t.MaxRects) ||
+		(float64(stats.Pixels) > t.FlipRatio*float64(screenArea)) {
+		stats.Flipped = true
+		stats.Pixels = screenArea
+		status = screen.Flip()
+	} else if len(rects) > 0 {
+		stats.Rects = len(rects)
+		screen.UpdateRects(rects)
+	}
+
+	t.mutex.Lock()
+	t.stats = stats
+	t.mutex.Unlock()
+
+	return status
+}
+
+// Returns the statistics of the most recent call to Present.
+func (t *DirtyTracker) Stats() DirtyStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.stats
+}
@@ -0,0 +1,188 @@
+package font
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Loads a font from a BDF (Glyph Bitmap Distribution Format) file.
+func LoadBDFFile(file string) (*Font, error) {
+	r, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return LoadBDF(r)
+}
+
+// Reads a font in BDF (Glyph Bitmap Distribution Format). Characters with
+// an ENCODING of -1 are skipped.
+func LoadBDF(r io.Reader) (*Font, error) {
+	var (
+		f           = NewFont(0, 0)
+		ascent      = -1
+		descent     = -1
+		bbx         [4]int // Font bounding box: width, height, x offset, y offset
+		defaultDW   int
+		glyph       *Glyph
+		encoding    int
+		bitmapRow   = -1
+		haveGlyphDW bool
+	)
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		fail := func(format string, args ...interface{}) error {
+			return fmt.Errorf("font: BDF line %d: %s", line, fmt.Sprintf(format, args...))
+		}
+
+		ints := func(n int) ([]int, error) {
+			if len(fields) < n+1 {
+				return nil, fail("%s needs %d values", fields[0], n)
+			}
+			values := make([]int, n)
+			for i := range values {
+				v, err := strconv.Atoi(fields[i+1])
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				values[i] = v
+			}
+			return values, nil
+		}
+
+		// Rows of a glyph bitmap
+		if bitmapRow >= 0 && fields[0] != "ENDCHAR" {
+			mask := glyph.Mask
+			if mask == nil || bitmapRow >= mask.Rect.Dy() {
+				return nil, fail("too many bitmap rows")
+			}
+			bits, err := hex.DecodeString(fields[0])
+			if err != nil {
+				return nil, fail("invalid bitmap row: %v", err)
+			}
+			// Rows are padded to whole bytes, the leftmost pixel is the highest bit
+			for x := 0; x < mask.Rect.Dx() && x < 8*len(bits); x++ {
+				if bits[x/8]&(0x80>>uint(x%8)) != 0 {
+					mask.Pix[bitmapRow*mask.Stride+x] = 0xff
+				}
+			}
+			bitmapRow++
+			continue
+		}
+
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			v, err := ints(4)
+			if err != nil {
+				return nil, err
+			}
+			copy(bbx[:], v)
+
+		case "FONT_ASCENT":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			ascent = v[0]
+
+		case "FONT_DESCENT":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			descent = v[0]
+
+		case "DWIDTH":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			if glyph != nil {
+				glyph.Advance = v[0]
+				haveGlyphDW = true
+			} else {
+				defaultDW = v[0]
+			}
+
+		case "STARTCHAR":
+			glyph = &Glyph{Advance: defaultDW}
+			encoding = -1
+			haveGlyphDW = false
+
+		case "ENCODING":
+			v, err := ints(1)
+			if err != nil {
+				return nil, err
+			}
+			encoding = v[0]
+
+		case "BBX":
+			if glyph == nil {
+				return nil, fail("BBX outside of a character")
+			}
+			v, err := ints(4)
+			if err != nil {
+				return nil, err
+			}
+			if v[0] < 0 || v[1] < 0 {
+				return nil, fail("invalid BBX")
+			}
+			if v[0] > 0 && v[1] > 0 {
+				glyph.Mask = image.NewAlpha(image.Rect(0, 0, v[0], v[1]))
+			}
+			glyph.Origin = image.Pt(v[2], -(v[3] + v[1]))
+			if !haveGlyphDW && glyph.Advance == 0 {
+				glyph.Advance = v[0] + v[2]
+			}
+
+		case "BITMAP":
+			if glyph == nil {
+				return nil, fail("BITMAP outside of a character")
+			}
+			bitmapRow = 0
+
+		case "ENDCHAR":
+			if glyph == nil {
+				return nil, fail("ENDCHAR outside of a character")
+			}
+			if encoding >= 0 {
+				f.Glyphs[rune(encoding)] = glyph
+			}
+			glyph = nil
+			bitmapRow = -1
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(f.Glyphs) == 0 {
+		return nil, errors.New("font: BDF file contains no characters")
+	}
+
+	// Fall back to the font bounding box if the properties are missing
+	if ascent < 0 {
+		ascent = bbx[1] + bbx[3]
+	}
+	if descent < 0 {
+		descent = -bbx[3]
+	}
+	f.Ascent, f.Descent = ascent, descent
+
+	return f, nil
+}
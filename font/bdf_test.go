@@ -0,0 +1,174 @@
+package font
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--8-80-75-75-c-50-iso10646-1
+SIZE 8 75 75
+FONTBOUNDINGBOX 5 8 0 -2
+STARTPROPERTIES 2
+FONT_ASCENT 6
+FONT_DESCENT 2
+ENDPROPERTIES
+DWIDTH 5 0
+CHARS 3
+STARTCHAR A
+ENCODING 65
+SWIDTH 625 0
+DWIDTH 6 0
+BBX 3 4 1 0
+BITMAP
+40
+A0
+E0
+A0
+ENDCHAR
+STARTCHAR underscore
+ENCODING 95
+BBX 4 1 0 -2
+BITMAP
+F0
+ENDCHAR
+STARTCHAR unencoded
+ENCODING -1
+BBX 1 1 0 0
+BITMAP
+80
+ENDCHAR
+STARTCHAR space
+ENCODING 32
+BBX 0 0 0 0
+BITMAP
+ENDCHAR
+ENDFONT
+`
+
+// Returns the mask as rows of '#' and '.'.
+func maskRows(mask *image.Alpha) []string {
+	var rows []string
+	b := mask.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := ""
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if mask.AlphaAt(x, y).A != 0 {
+				row += "#"
+			} else {
+				row += "."
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestLoadBDF(t *testing.T) {
+	f, err := LoadBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (f.Ascent != 6) || (f.Descent != 2) {
+		t.Errorf("got ascent %d, descent %d, want 6, 2", f.Ascent, f.Descent)
+	}
+	if len(f.Glyphs) != 3 {
+		t.Errorf("got %d glyphs, want 3", len(f.Glyphs))
+	}
+
+	tests := []struct {
+		r       rune
+		rows    []string
+		origin  image.Point
+		advance int
+	}{
+		{'A', []string{".#.", "#.#", "###", "#.#"}, image.Pt(1, -4), 6},
+		{'_', []string{"####"}, image.Pt(0, 1), 5},
+		{' ', nil, image.Pt(0, 0), 5},
+	}
+	for _, test := range tests {
+		g := f.Glyphs[test.r]
+		if g == nil {
+			t.Errorf("%q: missing glyph", test.r)
+			continue
+		}
+		var rows []string
+		if g.Mask != nil {
+			rows = maskRows(g.Mask)
+		}
+		if strings.Join(rows, "\n") != strings.Join(test.rows, "\n") {
+			t.Errorf("%q: got mask %q, want %q", test.r, rows, test.rows)
+		}
+		if (g.Origin != test.origin) || (g.Advance != test.advance) {
+			t.Errorf("%q: got origin %v, advance %d, want %v, %d", test.r, g.Origin, g.Advance, test.origin, test.advance)
+		}
+	}
+}
+
+func TestLoadBDFBoundingBox(t *testing.T) {
+	// Without FONT_ASCENT and FONT_DESCENT, and without DWIDTH
+	bdf := `FONTBOUNDINGBOX 6 10 0 -3
+STARTCHAR x
+ENCODING 120
+BBX 4 2 1 0
+BITMAP
+90
+60
+ENDCHAR
+`
+	f, err := LoadBDF(strings.NewReader(bdf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (f.Ascent != 7) || (f.Descent != 3) {
+		t.Errorf("got ascent %d, descent %d, want 7, 3", f.Ascent, f.Descent)
+	}
+	if g := f.Glyphs['x']; g.Advance != 5 {
+		t.Errorf("got advance %d, want 5", g.Advance)
+	}
+}
+
+func TestLoadBDFWide(t *testing.T) {
+	// A 72 pixel wide row does not fit into 64 bits
+	bdf := `STARTCHAR wide
+ENCODING 87
+BBX 72 1 0 0
+BITMAP
+8000000000000000A1
+ENDCHAR
+`
+	f, err := LoadBDF(strings.NewReader(bdf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#" + strings.Repeat(".", 63) + "#.#....#"
+	if rows := maskRows(f.Glyphs['W'].Mask); (len(rows) != 1) || (rows[0] != want) {
+		t.Errorf("got mask %q, want %q", rows, want)
+	}
+}
+
+func TestLoadBDFErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		bdf  string
+	}{
+		{"empty", ""},
+		{"only unencoded", "STARTCHAR a\nENCODING -1\nBBX 1 1 0 0\nBITMAP\n80\nENDCHAR\n"},
+		{"too many rows", "STARTCHAR a\nENCODING 97\nBBX 1 1 0 0\nBITMAP\n80\n80\nENDCHAR\n"},
+		{"invalid row", "STARTCHAR a\nENCODING 97\nBBX 1 1 0 0\nBITMAP\nZZ\nENDCHAR\n"},
+		{"invalid BBX", "STARTCHAR a\nENCODING 97\nBBX -1 1 0 0\nENDCHAR\n"},
+		{"short BBX", "STARTCHAR a\nENCODING 97\nBBX 1 1\nENDCHAR\n"},
+		{"invalid number", "FONT_ASCENT x\n"},
+		{"BBX outside of a character", "BBX 1 1 0 0\n"},
+		{"BITMAP outside of a character", "BITMAP\n"},
+		{"ENDCHAR outside of a character", "ENDCHAR\n"},
+	}
+
+	for _, test := range tests {
+		if _, err := LoadBDF(strings.NewReader(test.bdf)); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
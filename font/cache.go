@@ -0,0 +1,98 @@
+package font
+
+import (
+	"container/list"
+	"image/color"
+	"sdl"
+)
+
+// Keeps the surfaces of recently rendered strings, so that text which is
+// drawn every frame only has to be rendered when it changes.
+type Cache struct {
+	font    *Font
+	size    int
+	lru     *list.List // Most recently used entries at the front
+	entries map[cacheKey]*list.Element
+}
+
+type cacheKey struct {
+	text        string
+	color       color.NRGBA
+	align       Align
+	width       int
+	lineSpacing int
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	surface *sdl.Surface
+}
+
+// Creates a cache for text rendered with the font, which holds the surfaces
+// of up to size strings.
+func NewCache(f *Font, size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		font:    f,
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[cacheKey]*list.Element),
+	}
+}
+
+// Returns the surface of the rendered text, rendering it only if it is not
+// cached yet. The surface belongs to the cache: it must not be freed or
+// modified, and it is only valid until it is evicted from the cache.
+func (c *Cache) Render(text string, opts *Options) *sdl.Surface {
+	if opts == nil {
+		opts = &Options{}
+	}
+	key := cacheKey{text: text, align: opts.Align, width: opts.Width, lineSpacing: opts.LineSpacing}
+	if opts.Color != nil {
+		key.color = color.NRGBAModel.Convert(opts.Color).(color.NRGBA)
+	} else {
+		key.color = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	}
+
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).surface
+	}
+
+	o := *opts
+	o.Color = key.color
+	s := c.font.Render(text, &o)
+	if s == nil {
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key, s})
+	for c.lru.Len() > c.size {
+		c.evict(c.lru.Back())
+	}
+
+	return s
+}
+
+// Draws the text onto dst with the top-left corner at x, y.
+func (c *Cache) Draw(dst *sdl.Surface, x, y int, text string, opts *Options) {
+	s := c.Render(text, opts)
+	if s != nil {
+		dst.Blit(&sdl.Rect{X: int16(x), Y: int16(y)}, s, nil)
+	}
+}
+
+// Frees all cached surfaces.
+func (c *Cache) Clear() {
+	for c.lru.Len() > 0 {
+		c.evict(c.lru.Back())
+	}
+}
+
+func (c *Cache) evict(e *list.Element) {
+	entry := c.lru.Remove(e).(*cacheEntry)
+	delete(c.entries, entry.key)
+	entry.surface.Free()
+}
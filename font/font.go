@@ -0,0 +1,101 @@
+/*
+Package font renders text with bitmap fonts into SDL surfaces.
+
+Fonts can be read from BDF files (LoadBDF) or from images containing a grid
+of characters (LoadGrid). A small built-in font (Default) is always
+available, so text can be shown without any assets.
+
+Text is rendered by Font.Render into a new 32-bit surface with an alpha
+channel, which can be blitted onto any other surface. Layout is controlled
+by Options: color, alignment, line spacing and word wrapping. A Cache keeps
+rendered surfaces around so that text which is redrawn every frame, such as
+a HUD, costs no more than a blit.
+*/
+package font
+
+import "image"
+
+// A single character of a font.
+type Glyph struct {
+	// Coverage of the pixels of the glyph; nil for blank glyphs such as space
+	Mask *image.Alpha
+
+	// Position of the top-left corner of Mask relative to the pen position,
+	// which lies on the baseline. Y is usually negative.
+	Origin image.Point
+
+	// Horizontal distance from this pen position to the next one
+	Advance int
+}
+
+// A bitmap font.
+type Font struct {
+	Ascent  int // Height of the font above the baseline
+	Descent int // Height of the font below the baseline
+
+	Glyphs map[rune]*Glyph
+
+	// Adjustments of the advance between pairs of characters, in pixels.
+	// Negative values move the characters closer together.
+	Kerning map[[2]rune]int
+
+	// The character shown in place of characters the font has no glyph
+	// for. If the font has no glyph for it either, such characters are
+	// skipped.
+	Missing rune
+}
+
+// Creates an empty font with the given ascent and descent.
+func NewFont(ascent, descent int) *Font {
+	return &Font{
+		Ascent:  ascent,
+		Descent: descent,
+		Glyphs:  make(map[rune]*Glyph),
+		Kerning: make(map[[2]rune]int),
+		Missing: '?',
+	}
+}
+
+// Returns the distance between the baselines of two lines of text.
+func (f *Font) Height() int {
+	return f.Ascent + f.Descent
+}
+
+// Sets the kerning adjustment between the characters left and right.
+func (f *Font) SetKerning(left, right rune, adjust int) {
+	if f.Kerning == nil {
+		f.Kerning = make(map[[2]rune]int)
+	}
+	if adjust == 0 {
+		delete(f.Kerning, [2]rune{left, right})
+	} else {
+		f.Kerning[[2]rune{left, right}] = adjust
+	}
+}
+
+// Returns the glyph for the character, the glyph of the Missing character
+// if the font lacks it, or nil.
+func (f *Font) Glyph(r rune) *Glyph {
+	if g, ok := f.Glyphs[r]; ok {
+		return g
+	}
+	return f.Glyphs[f.Missing]
+}
+
+// Returns the width of a single line of text in pixels, including kerning.
+func (f *Font) Width(line string) int {
+	width := 0
+	prev := rune(-1)
+	for _, r := range line {
+		g := f.Glyph(r)
+		if g == nil {
+			continue
+		}
+		if prev >= 0 {
+			width += f.Kerning[[2]rune{prev, r}]
+		}
+		width += g.Advance
+		prev = r
+	}
+	return width
+}
@@ -0,0 +1,99 @@
+package font
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"unicode/utf8"
+)
+
+// Creates a font from an image which contains the characters in a grid of
+// cellWidth by cellHeight pixels, row by row. The string chars lists the
+// characters of the cells in the same order.
+//
+// If the image has transparent pixels, the alpha channel defines the shape
+// of the characters; otherwise the brightness does, so the characters should
+// be light on a dark background.
+//
+// If proportional is false, every character advances by cellWidth. If it is
+// true, empty columns are trimmed from both sides of each character and it
+// advances by its remaining width plus one pixel; characters without any
+// pixels (such as space) advance by half of cellWidth.
+func LoadGrid(img image.Image, cellWidth, cellHeight int, chars string, proportional bool) (*Font, error) {
+	b := img.Bounds()
+	if (cellWidth <= 0) || (cellHeight <= 0) {
+		return nil, errors.New("font: invalid cell size")
+	}
+	columns := b.Dx() / cellWidth
+	rows := b.Dy() / cellHeight
+	if utf8.RuneCountInString(chars) > columns*rows {
+		return nil, errors.New("font: more characters than cells in the image")
+	}
+
+	byAlpha := !opaque(img)
+	f := NewFont(cellHeight, 0)
+
+	i := 0
+	for _, r := range chars {
+		cell := image.Rect(0, 0, cellWidth, cellHeight).Add(b.Min).Add(image.Pt(i%columns*cellWidth, i/columns*cellHeight))
+		i++
+
+		mask := image.NewAlpha(image.Rect(0, 0, cellWidth, cellHeight))
+		left, right := cellWidth, -1
+		for y := 0; y < cellHeight; y++ {
+			for x := 0; x < cellWidth; x++ {
+				a := coverage(img.At(cell.Min.X+x, cell.Min.Y+y), byAlpha)
+				mask.Pix[y*mask.Stride+x] = a
+				if a != 0 {
+					if x < left {
+						left = x
+					}
+					if x > right {
+						right = x
+					}
+				}
+			}
+		}
+
+		g := &Glyph{Mask: mask, Origin: image.Pt(0, -cellHeight), Advance: cellWidth}
+		if proportional {
+			if right < left {
+				g.Mask = nil
+				g.Advance = cellWidth / 2
+			} else {
+				g.Mask = mask.SubImage(image.Rect(left, 0, right+1, cellHeight)).(*image.Alpha)
+				g.Advance = right - left + 2
+			}
+		}
+		f.Glyphs[r] = g
+	}
+
+	return f, nil
+}
+
+// Returns true if the image has no transparent pixels.
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface {
+		Opaque() bool
+	}); ok {
+		return o.Opaque()
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Returns the coverage of a pixel of a font sheet.
+func coverage(c color.Color, byAlpha bool) uint8 {
+	if byAlpha {
+		return color.AlphaModel.Convert(c).(color.Alpha).A
+	}
+	return color.GrayModel.Convert(c).(color.Gray).Y
+}
@@ -0,0 +1,198 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"sdl"
+	"strings"
+	"unicode/utf8"
+)
+
+// Horizontal alignment of lines of text
+type Align int
+
+const (
+	Left Align = iota
+	Center
+	Right
+)
+
+// Options controlling the layout and appearance of rendered text.
+// The zero value renders white, left aligned text without wrapping.
+type Options struct {
+	Color       color.Color // Color of the text, white if nil
+	Align       Align       // Alignment of the lines within the width of the text
+	Width       int         // Wrap lines longer than this many pixels; 0 disables wrapping
+	LineSpacing int         // Extra pixels between lines, may be negative
+}
+
+// Splits text into lines at newlines and, if width is positive, wraps lines
+// at spaces so that they fit into width pixels. Words wider than width are
+// broken between characters.
+func (f *Font) Lines(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		if width <= 0 {
+			lines = append(lines, paragraph)
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if f.Width(candidate) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+
+			// Break words which do not fit on a line of their own
+			for f.Width(word) > width {
+				n := f.fit(word, width)
+				if n == len(word) {
+					// A single character wider than the line
+					break
+				}
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Returns the length in bytes of the longest prefix of s which fits into
+// width pixels, but at least one character.
+func (f *Font) fit(s string, width int) int {
+	_, n := utf8.DecodeRuneInString(s)
+	for i := range s {
+		if i <= n {
+			continue
+		}
+		if f.Width(s[:i]) > width {
+			break
+		}
+		n = i
+	}
+	return n
+}
+
+// Returns the size in pixels of the text laid out with the options.
+func (f *Font) Measure(text string, opts *Options) (w, h int) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	lines := f.Lines(text, opts.Width)
+	return f.measureLines(lines, opts)
+}
+
+func (f *Font) measureLines(lines []string, opts *Options) (w, h int) {
+	for _, line := range lines {
+		if lw := f.Width(line); lw > w {
+			w = lw
+		}
+	}
+	h = len(lines)*f.Height() + (len(lines)-1)*opts.LineSpacing
+	if h < 0 {
+		h = 0
+	}
+	return w, h
+}
+
+// Renders the text into a new 32-bit surface with an alpha channel, which is
+// transparent except for the text. Lines are aligned within the wrapping
+// width if there is one, or else within the widest line.
+//
+// Returns nil on error. The surface should be freed by the caller.
+func (f *Font) Render(text string, opts *Options) *sdl.Surface {
+	return sdl.NewSurfaceFromImage(f.RenderImage(text, opts))
+}
+
+// Renders the text like Render, but into an image.
+func (f *Font) RenderImage(text string, opts *Options) *image.NRGBA {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var c color.NRGBA
+	if opts.Color != nil {
+		c = color.NRGBAModel.Convert(opts.Color).(color.NRGBA)
+	} else {
+		c = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	}
+
+	lines := f.Lines(text, opts.Width)
+	w, h := f.measureLines(lines, opts)
+	if opts.Width > 0 {
+		w = opts.Width
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, maxInt(w, 1), maxInt(h, 1)))
+
+	for i, line := range lines {
+		x := 0
+		switch opts.Align {
+		case Center:
+			x = (w - f.Width(line)) / 2
+		case Right:
+			x = w - f.Width(line)
+		}
+		y := i*(f.Height()+opts.LineSpacing) + f.Ascent
+		f.drawLine(img, x, y, line, c)
+	}
+
+	return img
+}
+
+// Draws a line of text with the pen starting at x on the baseline y.
+func (f *Font) drawLine(img *image.NRGBA, x, y int, line string, c color.NRGBA) {
+	prev := rune(-1)
+	for _, r := range line {
+		g := f.Glyph(r)
+		if g == nil {
+			continue
+		}
+		if prev >= 0 {
+			x += f.Kerning[[2]rune{prev, r}]
+		}
+		prev = r
+
+		if g.Mask != nil {
+			mb := g.Mask.Bounds()
+			for my := mb.Min.Y; my < mb.Max.Y; my++ {
+				for mx := mb.Min.X; mx < mb.Max.X; mx++ {
+					m := uint32(g.Mask.AlphaAt(mx, my).A)
+					if m == 0 {
+						continue
+					}
+					px := x + g.Origin.X + mx - mb.Min.X
+					py := y + g.Origin.Y + my - mb.Min.Y
+					if !(image.Point{px, py}.In(img.Rect)) {
+						continue
+					}
+					// Overlapping glyphs keep the stronger coverage
+					a := uint8(uint32(c.A) * m / 0xff)
+					if a > img.NRGBAAt(px, py).A {
+						img.SetNRGBA(px, py, color.NRGBA{c.R, c.G, c.B, a})
+					}
+				}
+			}
+		}
+
+		x += g.Advance
+	}
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,72 @@
+package font
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	// Every character of the built-in font is 7 pixels wide
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"", 0, []string{""}},
+		{"a b\nc", 0, []string{"a b", "c"}},
+		{"a\n", 0, []string{"a", ""}},
+		{"aa bb cc", 35, []string{"aa bb", "cc"}},
+		{"aa bb cc", 34, []string{"aa", "bb", "cc"}},
+		{"aa bb\ncc dd", 100, []string{"aa bb", "cc dd"}},
+		{"a  b", 100, []string{"a  b"}},
+		{"abcdefgh", 21, []string{"abc", "def", "gh"}},
+		{"a abcdefgh", 21, []string{"a", "abc", "def", "gh"}},
+		{"abcdefg x", 21, []string{"abc", "def", "g x"}},
+		{"ab", 3, []string{"a", "b"}},
+		{"ééé", 14, []string{"éé", "é"}},
+	}
+
+	f := Default()
+	for _, test := range tests {
+		got := f.Lines(test.text, test.width)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Lines(%q, %d) = %q, want %q", test.text, test.width, got, test.want)
+		}
+	}
+}
+
+func TestLinesKerning(t *testing.T) {
+	f := Default().Copy()
+	f.SetKerning('a', 'v', -2)
+
+	if w := f.Width("ava"); w != 7*3-2 {
+		t.Errorf("Width = %d, want %d", w, 7*3-2)
+	}
+	want := []string{"av", "a"}
+	if got := f.Lines("ava", 12); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines = %q, want %q", got, want)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	f := Default()
+	w, h := f.Measure("aa bb cc", &Options{Width: 35, LineSpacing: 2})
+	if (w != 35) || (h != 2*13+2) {
+		t.Errorf("got %dx%d, want 35x28", w, h)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	f := Default()
+	c := f.Copy()
+
+	g := c.Glyphs['A']
+	g.Mask.Pix[0] = 0x80
+	g.Advance = 100
+	c.SetKerning('A', 'V', -1)
+
+	orig := f.Glyphs['A']
+	if (orig.Mask.Pix[0] != 0) || (orig.Advance != 7) || (len(f.Kerning) != 0) {
+		t.Error("changing the copy changed the original font")
+	}
+}
@@ -0,0 +1,174 @@
+package sdl
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL.h>
+import "C"
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Creates a PixelFormat for an RGB or RGBA format with the given number of
+// bits per pixel and component masks. The shifts and losses are derived from
+// the masks. Palettized formats cannot be created this way.
+func NewPixelFormat(bpp int, Rmask, Gmask, Bmask, Amask uint32) *PixelFormat {
+	f := &PixelFormat{
+		BitsPerPixel:  uint8(bpp),
+		BytesPerPixel: uint8((bpp + 7) / 8),
+		Rmask:         Rmask,
+		Gmask:         Gmask,
+		Bmask:         Bmask,
+		Amask:         Amask,
+		Alpha:         0xff,
+	}
+	f.Rshift, f.Rloss = maskShiftLoss(Rmask)
+	f.Gshift, f.Gloss = maskShiftLoss(Gmask)
+	f.Bshift, f.Bloss = maskShiftLoss(Bmask)
+	f.Ashift, f.Aloss = maskShiftLoss(Amask)
+	return f
+}
+
+// Returns the position of the lowest bit of the mask, and the number of bits
+// the mask has less than 8.
+func maskShiftLoss(mask uint32) (shift, loss uint8) {
+	if mask == 0 {
+		return 0, 8
+	}
+	for mask&1 == 0 {
+		mask >>= 1
+		shift++
+	}
+	loss = 8
+	for (mask&1 == 1) && (loss > 0) {
+		mask >>= 1
+		loss--
+	}
+	return shift, loss
+}
+
+// Standard formats. The names list the components from the most to the
+// least significant bits of a pixel value, except for RGB24 and BGR24 which
+// list them in byte order.
+
+// 32-bit format with red in the most significant byte and alpha in the least.
+func FormatRGBA8888() *PixelFormat {
+	return NewPixelFormat(32, 0xff000000, 0x00ff0000, 0x0000ff00, 0x000000ff)
+}
+
+// 32-bit format with alpha in the most significant byte and blue in the least.
+func FormatARGB8888() *PixelFormat {
+	return NewPixelFormat(32, 0x00ff0000, 0x0000ff00, 0x000000ff, 0xff000000)
+}
+
+// 32-bit format with alpha in the most significant byte and red in the least.
+// On little-endian hosts, this is the byte order of image.RGBA.
+func FormatABGR8888() *PixelFormat {
+	return NewPixelFormat(32, 0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000)
+}
+
+// 32-bit format with blue in the most significant byte and alpha in the least.
+func FormatBGRA8888() *PixelFormat {
+	return NewPixelFormat(32, 0x0000ff00, 0x00ff0000, 0xff000000, 0x000000ff)
+}
+
+// 32-bit format without alpha and with red in the second most significant byte.
+func FormatRGB888() *PixelFormat {
+	return NewPixelFormat(32, 0x00ff0000, 0x0000ff00, 0x000000ff, 0)
+}
+
+// 24-bit format storing red, green and blue bytes in this order.
+func FormatRGB24() *PixelFormat {
+	if nativeLittleEndian {
+		return NewPixelFormat(24, 0x0000ff, 0x00ff00, 0xff0000, 0)
+	}
+	return NewPixelFormat(24, 0xff0000, 0x00ff00, 0x0000ff, 0)
+}
+
+// 24-bit format storing blue, green and red bytes in this order.
+func FormatBGR24() *PixelFormat {
+	if nativeLittleEndian {
+		return NewPixelFormat(24, 0xff0000, 0x00ff00, 0x0000ff, 0)
+	}
+	return NewPixelFormat(24, 0x0000ff, 0x00ff00, 0xff0000, 0)
+}
+
+// 16-bit format with 5 bits of red, 6 bits of green and 5 bits of blue.
+func FormatRGB565() *PixelFormat {
+	return NewPixelFormat(16, 0xf800, 0x07e0, 0x001f, 0)
+}
+
+// 16-bit format with 5 bits for each of red, green and blue.
+func FormatRGB555() *PixelFormat {
+	return NewPixelFormat(16, 0x7c00, 0x03e0, 0x001f, 0)
+}
+
+// Returns true if both formats store pixels the same way, ignoring the
+// per-surface colorkey and alpha. Palettized formats must have equal palettes.
+func (f *PixelFormat) Equal(g *PixelFormat) bool {
+	if (f.BitsPerPixel != g.BitsPerPixel) || (f.BytesPerPixel != g.BytesPerPixel) ||
+		(f.Rmask != g.Rmask) || (f.Gmask != g.Gmask) || (f.Bmask != g.Bmask) || (f.Amask != g.Amask) {
+		return false
+	}
+
+	if (f.Palette == nil) || (g.Palette == nil) {
+		return f.Palette == g.Palette
+	}
+	if f.Palette.Ncolors != g.Palette.Ncolors {
+		return false
+	}
+	for i := 0; i < int(f.Palette.Ncolors); i++ {
+		if f.Palette.color(i) != g.Palette.color(i) {
+			return false
+		}
+	}
+	return true
+}
+
+// Describes the format, using the name of the standard format if it
+// matches one, for example "RGBA8888" or "8bpp palettized (256 colors)".
+func (f *PixelFormat) String() string {
+	if f.Palette != nil {
+		return fmt.Sprintf("%dbpp palettized (%d colors)", f.BitsPerPixel, f.Palette.Ncolors)
+	}
+
+	standard := []struct {
+		name   string
+		format *PixelFormat
+	}{
+		{"RGBA8888", FormatRGBA8888()},
+		{"ARGB8888", FormatARGB8888()},
+		{"ABGR8888", FormatABGR8888()},
+		{"BGRA8888", FormatBGRA8888()},
+		{"RGB888", FormatRGB888()},
+		{"RGB24", FormatRGB24()},
+		{"BGR24", FormatBGR24()},
+		{"RGB565", FormatRGB565()},
+		{"RGB555", FormatRGB555()},
+	}
+	for _, s := range standard {
+		if f.Equal(s.format) {
+			return s.name
+		}
+	}
+
+	return fmt.Sprintf("%dbpp R:%08x G:%08x B:%08x A:%08x", f.BitsPerPixel, f.Rmask, f.Gmask, f.Bmask, f.Amask)
+}
+
+// Creates a new surface of the specified format and copies the surface into
+// it. Unlike DisplayFormat, this does not need a video mode. The flags are
+// the same as for CreateRGBSurface; include SRCCOLORKEY or SRCALPHA to carry
+// over the colorkey or alpha settings of the surface.
+//
+// Returns nil on error.
+func (s *Surface) ConvertSurface(format *PixelFormat, flags uint32) *Surface {
+	s.mutex.RLock()
+	var p *C.SDL_Surface
+	if !s.freed("Surface.ConvertSurface") {
+		p = C.SDL_ConvertSurface(s.cSurface, (*C.SDL_PixelFormat)(unsafe.Pointer(format)), C.Uint32(flags))
+	}
+	s.mutex.RUnlock()
+	return wrap(p)
+}
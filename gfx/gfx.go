@@ -0,0 +1,259 @@
+/*
+Package gfx draws graphics primitives on SDL surfaces: pixels, lines,
+rectangles, circles, ellipses, arcs, pies, polygons and Bezier curves.
+
+All functions work with any PixelFormat and draw only inside the clipping
+rectangle of the surface (see Surface.SetClipRect). Colors which are not
+fully opaque are alpha-blended with the pixels of the surface. The surface
+is locked by every function, so it must not be locked by the caller.
+*/
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"sdl"
+)
+
+// The state of one drawing operation on a locked surface.
+type canvas struct {
+	s *sdl.Surface
+
+	// The clipping rectangle, x1 and y1 exclusive
+	x0, y0, x1, y1 int
+
+	c     color.NRGBA
+	pixel uint32 // c mapped to the format of the surface
+
+	// Pixels plotted so far by a translucent outline, so that shared
+	// points such as corners are not blended twice
+	plotted map[image.Point]bool
+}
+
+// Locks the surface and calls f with a canvas for the color c. Nothing is
+// drawn on a freed surface.
+func draw(s *sdl.Surface, c color.Color, f func(cv *canvas)) {
+	if s.Format == nil {
+		sdl.SetError("gfx: the surface has been freed")
+		return
+	}
+
+	var clip sdl.Rect
+	s.GetClipRect(&clip)
+
+	cv := &canvas{
+		s:  s,
+		x0: int(clip.X),
+		y0: int(clip.Y),
+		x1: int(clip.X) + int(clip.W),
+		y1: int(clip.Y) + int(clip.H),
+		c:  color.NRGBAModel.Convert(c).(color.NRGBA),
+	}
+	if cv.c.A == 0 {
+		return
+	}
+	cv.pixel = sdl.MapRGBA(s.Format, cv.c.R, cv.c.G, cv.c.B, cv.c.A)
+
+	s.LockPixels(func(pix []byte, pitch int) {
+		f(cv)
+	})
+}
+
+// Returns true if x, y is inside the clipping rectangle.
+func (cv *canvas) visible(x, y int) bool {
+	return (x >= cv.x0) && (y >= cv.y0) && (x < cv.x1) && (y < cv.y1)
+}
+
+// Blends the color with the pixel at x, y, with the alpha of the color
+// scaled by coverage (0 - 255). The coordinates must be visible.
+func (cv *canvas) blend(x, y int, coverage uint32) {
+	a := uint32(cv.c.A) * coverage / 255
+	if a == 0xff {
+		cv.s.SetPixel(x, y, cv.pixel)
+		return
+	}
+	if a == 0 {
+		return
+	}
+
+	// Porter-Duff "over" with colors which are not premultiplied
+	d := cv.s.At(x, y).(color.NRGBA)
+	da := uint32(d.A) * (0xff - a) / 0xff
+	outA := a + da
+	mix := func(c, d uint8) uint8 {
+		return uint8((uint32(c)*a + uint32(d)*da) / outA)
+	}
+	cv.s.Set(x, y, color.NRGBA{mix(cv.c.R, d.R), mix(cv.c.G, d.G), mix(cv.c.B, d.B), uint8(outA)})
+}
+
+// Plots a single point of an outline.
+func (cv *canvas) plot(x, y int) {
+	cv.plotCoverage(x, y, 0xff)
+}
+
+// Plots a single point of an outline with partial coverage (0 - 255).
+func (cv *canvas) plotCoverage(x, y int, coverage uint32) {
+	if !cv.visible(x, y) {
+		return
+	}
+	if cv.c.A != 0xff {
+		if cv.plotted == nil {
+			cv.plotted = make(map[image.Point]bool)
+		}
+		p := image.Point{x, y}
+		if cv.plotted[p] {
+			return
+		}
+		cv.plotted[p] = true
+	}
+	cv.blend(x, y, coverage)
+}
+
+// Fills the pixels x0 to x1 (inclusive) of row y. Spans of filled shapes
+// never overlap, so they bypass the bookkeeping of plot; pixels which an
+// outline has plotted before are skipped.
+func (cv *canvas) span(x0, x1, y int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if (y < cv.y0) || (y >= cv.y1) {
+		return
+	}
+	if x0 < cv.x0 {
+		x0 = cv.x0
+	}
+	if x1 >= cv.x1 {
+		x1 = cv.x1 - 1
+	}
+	for x := x0; x <= x1; x++ {
+		if (cv.plotted == nil) || !cv.plotted[image.Point{x, y}] {
+			cv.blend(x, y, 0xff)
+		}
+	}
+}
+
+// Draws a single pixel.
+func Pixel(s *sdl.Surface, x, y int, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.plot(x, y)
+	})
+}
+
+// Draws a horizontal line from x0 to x1 (inclusive).
+func HLine(s *sdl.Surface, x0, x1, y int, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.span(x0, x1, y)
+	})
+}
+
+// Draws a vertical line from y0 to y1 (inclusive).
+func VLine(s *sdl.Surface, x, y0, y1 int, c color.Color) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	draw(s, c, func(cv *canvas) {
+		for y := y0; y <= y1; y++ {
+			cv.plot(x, y)
+		}
+	})
+}
+
+// Draws a line from x0, y0 to x1, y1 (inclusive) with Bresenham's algorithm.
+func Line(s *sdl.Surface, x0, y0, x1, y1 int, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.line(x0, y0, x1, y1)
+	})
+}
+
+func (cv *canvas) line(x0, y0, x1, y1 int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+	for {
+		cv.plot(x0, y0)
+		if (x0 == x1) && (y0 == y1) {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// Draws an antialiased line from x0, y0 to x1, y1 with Wu's algorithm.
+func AALine(s *sdl.Surface, x0, y0, x1, y1 int, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.aaline(float64(x0), float64(y0), float64(x1), float64(y1))
+	})
+}
+
+func (cv *canvas) aaline(x0, y0, x1, y1 float64) {
+	steep := abs64(y1-y0) > abs64(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		cv.plotCoverage(x, y, uint32(coverage*0xff+0.5))
+	}
+
+	gradient := 1.0
+	if x1 != x0 {
+		gradient = (y1 - y0) / (x1 - x0)
+	}
+
+	y := y0
+	for x := int(x0); x <= int(x1); x++ {
+		iy := floor(y)
+		f := y - float64(iy)
+		plot(x, iy, 1-f)
+		if f > 0 {
+			plot(x, iy+1, f)
+		}
+		y += gradient
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func abs64(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+	return 0
+}
+
+func floor(v float64) int {
+	i := int(v)
+	if float64(i) > v {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,130 @@
+package gfx
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sdl"
+	"sort"
+)
+
+// Draws the outline of the closed polygon with the given vertices.
+func Polygon(s *sdl.Surface, points []image.Point, c color.Color) {
+	if len(points) == 0 {
+		return
+	}
+	draw(s, c, func(cv *canvas) {
+		prev := points[len(points)-1]
+		for _, p := range points {
+			cv.line(prev.X, prev.Y, p.X, p.Y)
+			prev = p
+		}
+	})
+}
+
+// Draws an antialiased outline of the closed polygon with the given vertices.
+func AAPolygon(s *sdl.Surface, points []image.Point, c color.Color) {
+	if len(points) == 0 {
+		return
+	}
+	draw(s, c, func(cv *canvas) {
+		prev := points[len(points)-1]
+		for _, p := range points {
+			cv.aaline(float64(prev.X), float64(prev.Y), float64(p.X), float64(p.Y))
+			prev = p
+		}
+	})
+}
+
+// Draws the filled polygon with the given vertices, using the even-odd rule
+// for self-intersecting polygons. The filled area includes the outline drawn
+// by Polygon, so the bottom and right edges are covered as well.
+func FilledPolygon(s *sdl.Surface, points []image.Point, c color.Color) {
+	if len(points) < 3 {
+		return
+	}
+
+	ymin, ymax := points[0].Y, points[0].Y
+	for _, p := range points {
+		if p.Y < ymin {
+			ymin = p.Y
+		}
+		if p.Y > ymax {
+			ymax = p.Y
+		}
+	}
+
+	draw(s, c, func(cv *canvas) {
+		// The outline first, so that the spans skip its translucent pixels
+		prev := points[len(points)-1]
+		for _, p := range points {
+			cv.line(prev.X, prev.Y, p.X, p.Y)
+			prev = p
+		}
+
+		if ymin < cv.y0 {
+			ymin = cv.y0
+		}
+		if ymax >= cv.y1 {
+			ymax = cv.y1 - 1
+		}
+
+		xs := make([]float64, 0, len(points))
+		for y := ymin; y <= ymax; y++ {
+			xs = xs[:0]
+			prev := points[len(points)-1]
+			for _, p := range points {
+				if (prev.Y <= y && y < p.Y) || (p.Y <= y && y < prev.Y) {
+					t := float64(y-prev.Y) / float64(p.Y-prev.Y)
+					xs = append(xs, float64(prev.X)+t*float64(p.X-prev.X))
+				}
+				prev = p
+			}
+			sort.Float64s(xs)
+
+			for i := 0; i+1 < len(xs); i += 2 {
+				x0 := int(math.Ceil(xs[i]))
+				x1 := int(math.Ceil(xs[i+1])) - 1
+				if x0 <= x1 {
+					cv.span(x0, x1, y)
+				}
+			}
+		}
+	})
+}
+
+// Draws a Bezier curve with the given control points, approximated by
+// the given number of straight line segments. The curve starts at the first
+// and ends at the last point; any number of control points is allowed.
+func Bezier(s *sdl.Surface, points []image.Point, steps int, c color.Color) {
+	if (len(points) < 2) || (steps < 1) {
+		return
+	}
+
+	draw(s, c, func(cv *canvas) {
+		px, py := points[0].X, points[0].Y
+		for i := 1; i <= steps; i++ {
+			x, y := bezierPoint(points, float64(i)/float64(steps))
+			cv.line(px, py, x, y)
+			px, py = x, y
+		}
+	})
+}
+
+// Evaluates the Bezier curve at t (0 - 1) with de Casteljau's algorithm.
+func bezierPoint(points []image.Point, t float64) (int, int) {
+	xs := make([]float64, len(points))
+	ys := make([]float64, len(points))
+	for i, p := range points {
+		xs[i], ys[i] = float64(p.X), float64(p.Y)
+	}
+
+	for n := len(points) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			xs[i] += (xs[i+1] - xs[i]) * t
+			ys[i] += (ys[i+1] - ys[i]) * t
+		}
+	}
+
+	return int(math.Floor(xs[0] + 0.5)), int(math.Floor(ys[0] + 0.5))
+}
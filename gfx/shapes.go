@@ -0,0 +1,235 @@
+package gfx
+
+import (
+	"image/color"
+	"math"
+	"sdl"
+)
+
+// Draws the outline of the rectangle with the corners x0, y0 and x1, y1
+// (inclusive).
+func Rectangle(s *sdl.Surface, x0, y0, x1, y1 int, c color.Color) {
+	RoundedRectangle(s, x0, y0, x1, y1, 0, c)
+}
+
+// Draws the filled rectangle with the corners x0, y0 and x1, y1 (inclusive).
+func Box(s *sdl.Surface, x0, y0, x1, y1 int, c color.Color) {
+	RoundedBox(s, x0, y0, x1, y1, 0, c)
+}
+
+// Draws the outline of a rectangle with corners rounded by radius r.
+func RoundedRectangle(s *sdl.Surface, x0, y0, x1, y1, r int, c color.Color) {
+	x0, y0, x1, y1, r = roundedCorners(x0, y0, x1, y1, r)
+	draw(s, c, func(cv *canvas) {
+		cv.ellipse(x0+r, y0+r, x1-r, y1-r, r, r, false)
+	})
+}
+
+// Draws a filled rectangle with corners rounded by radius r.
+func RoundedBox(s *sdl.Surface, x0, y0, x1, y1, r int, c color.Color) {
+	x0, y0, x1, y1, r = roundedCorners(x0, y0, x1, y1, r)
+	draw(s, c, func(cv *canvas) {
+		cv.ellipse(x0+r, y0+r, x1-r, y1-r, r, r, true)
+	})
+}
+
+// Orders the corners and limits the radius to half of the shorter side.
+func roundedCorners(x0, y0, x1, y1, r int) (int, int, int, int, int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	if max := (x1 - x0) / 2; r > max {
+		r = max
+	}
+	if max := (y1 - y0) / 2; r > max {
+		r = max
+	}
+	if r < 0 {
+		r = 0
+	}
+	return x0, y0, x1, y1, r
+}
+
+// Draws the outline of a circle with center x, y and radius r.
+func Circle(s *sdl.Surface, x, y, r int, c color.Color) {
+	Ellipse(s, x, y, r, r, c)
+}
+
+// Draws a filled circle with center x, y and radius r.
+func FilledCircle(s *sdl.Surface, x, y, r int, c color.Color) {
+	FilledEllipse(s, x, y, r, r, c)
+}
+
+// Draws the outline of an ellipse with center x, y and radii rx and ry.
+func Ellipse(s *sdl.Surface, x, y, rx, ry int, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.ellipse(x, y, x, y, abs(rx), abs(ry), false)
+	})
+}
+
+// Draws a filled ellipse with center x, y and radii rx and ry.
+func FilledEllipse(s *sdl.Surface, x, y, rx, ry int, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.ellipse(x, y, x, y, abs(rx), abs(ry), true)
+	})
+}
+
+// Draws an ellipse whose four quadrants are centered on the corners of the
+// rectangle cx0, cy0 - cx1, cy1 and joined by straight lines. With a single
+// center this is a plain ellipse; with radii of 0 it is a rectangle.
+func (cv *canvas) ellipse(cx0, cy0, cx1, cy1, rx, ry int, filled bool) {
+	if filled {
+		for dy := 0; dy <= ry; dy++ {
+			hw := rx
+			if ry > 0 {
+				hw = int(float64(rx)*math.Sqrt(1-float64(dy*dy)/float64(ry*ry)) + 0.5)
+			}
+			cv.span(cx0-hw, cx1+hw, cy0-dy)
+			if cy1+dy != cy0-dy {
+				cv.span(cx0-hw, cx1+hw, cy1+dy)
+			}
+		}
+		for y := cy0 + 1; y < cy1; y++ {
+			cv.span(cx0-rx, cx1+rx, y)
+		}
+		return
+	}
+
+	ellipsePoints(rx, ry, func(x, y int) {
+		cv.plot(cx1+x, cy1+y)
+		cv.plot(cx0-x, cy1+y)
+		cv.plot(cx1+x, cy0-y)
+		cv.plot(cx0-x, cy0-y)
+	})
+	for x := cx0; x <= cx1; x++ {
+		cv.plot(x, cy0-ry)
+		cv.plot(x, cy1+ry)
+	}
+	for y := cy0; y <= cy1; y++ {
+		cv.plot(cx0-rx, y)
+		cv.plot(cx1+rx, y)
+	}
+}
+
+// Calls plot for each point of the first quadrant of an ellipse with radii
+// rx and ry centered at 0, 0, using the midpoint algorithm.
+func ellipsePoints(rx, ry int, plot func(x, y int)) {
+	if (rx == 0) || (ry == 0) {
+		for x := 0; x <= rx; x++ {
+			plot(x, 0)
+		}
+		for y := 0; y <= ry; y++ {
+			plot(0, y)
+		}
+		return
+	}
+
+	rx2, ry2 := rx*rx, ry*ry
+	x, y := 0, ry
+	px, py := 0, 2*rx2*y
+
+	// Region 1: the slope is flatter than -1
+	p := ry2 - rx2*ry + rx2/4
+	for px < py {
+		plot(x, y)
+		x++
+		px += 2 * ry2
+		if p < 0 {
+			p += ry2 + px
+		} else {
+			y--
+			py -= 2 * rx2
+			p += ry2 + px - py
+		}
+	}
+
+	// Region 2: the slope is steeper than -1
+	p = (ry2*(2*x+1)*(2*x+1))/4 + rx2*(y-1)*(y-1) - rx2*ry2
+	for y >= 0 {
+		plot(x, y)
+		y--
+		py -= 2 * rx2
+		if p > 0 {
+			p += rx2 - py
+		} else {
+			x++
+			px += 2 * ry2
+			p += rx2 - py + px
+		}
+	}
+}
+
+// Returns true if the angle of the point dx, dy (relative to the center,
+// y pointing down) lies within the arc from start to end degrees.
+func inArc(dx, dy int, start, end float64) bool {
+	if end-start >= 360 {
+		return true
+	}
+	angle := math.Atan2(float64(-dy), float64(dx)) * 180 / math.Pi
+	return math.Mod(math.Mod(angle-start, 360)+360, 360) <= math.Mod(math.Mod(end-start, 360)+360, 360)
+}
+
+// Returns the point on the circle at the given angle.
+func arcPoint(x, y, r int, angle float64) (int, int) {
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	return x + int(math.Floor(float64(r)*cos+0.5)), y - int(math.Floor(float64(r)*sin+0.5))
+}
+
+// Draws the part of the circle with center x, y and radius r from the angle
+// start to end. Angles are in degrees, counterclockwise from the positive
+// x axis.
+func Arc(s *sdl.Surface, x, y, r int, start, end float64, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.arc(x, y, r, start, end)
+	})
+}
+
+func (cv *canvas) arc(x, y, r int, start, end float64) {
+	ellipsePoints(r, r, func(dx, dy int) {
+		for _, p := range [4][2]int{{dx, dy}, {-dx, dy}, {dx, -dy}, {-dx, -dy}} {
+			if inArc(p[0], p[1], start, end) {
+				cv.plot(x+p[0], y+p[1])
+			}
+		}
+	})
+}
+
+// Draws the outline of a pie slice of the circle with center x, y and
+// radius r, from the angle start to end. See func Arc.
+func Pie(s *sdl.Surface, x, y, r int, start, end float64, c color.Color) {
+	draw(s, c, func(cv *canvas) {
+		cv.arc(x, y, r, start, end)
+		if end-start < 360 {
+			x0, y0 := arcPoint(x, y, r, start)
+			x1, y1 := arcPoint(x, y, r, end)
+			cv.line(x, y, x0, y0)
+			cv.line(x, y, x1, y1)
+		}
+	})
+}
+
+// Draws a filled pie slice of the circle with center x, y and radius r,
+// from the angle start to end. See func Arc.
+func FilledPie(s *sdl.Surface, x, y, r int, start, end float64, c color.Color) {
+	r = abs(r)
+	draw(s, c, func(cv *canvas) {
+		for dy := -r; dy <= r; dy++ {
+			hw := int(math.Sqrt(float64(r*r-dy*dy)) + 0.5)
+			spanStart := 0
+			inside := false
+			for dx := -hw; dx <= hw+1; dx++ {
+				in := (dx <= hw) && (((dx == 0) && (dy == 0)) || inArc(dx, dy, start, end))
+				switch {
+				case in && !inside:
+					spanStart = dx
+				case !in && inside:
+					cv.span(x+spanStart, x+dx-1, y+dy)
+				}
+				inside = in
+			}
+		}
+	})
+}
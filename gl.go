@@ -0,0 +1,125 @@
+package sdl
+
+// The OpenGL attributes of a display. Start from DefaultGLConfig, which has
+// the values SDL uses when no attribute is set:
+//
+//	config := sdl.DefaultGLConfig()
+//	config.DepthSize = 24
+//	screen := config.SetVideoMode(640, 480, 32, 0)
+//
+// Without a GPU, Mesa's software rasterizer can be selected by setting the
+// environment variable LIBGL_ALWAYS_SOFTWARE=1. The program testgl/test.go
+// checks that the attributes can be read back this way.
+type GLConfig struct {
+	RedSize, GreenSize, BlueSize, AlphaSize                     int
+	BufferSize                                                  int
+	DoubleBuffer                                                bool
+	DepthSize, StencilSize                                      int
+	AccumRedSize, AccumGreenSize, AccumBlueSize, AccumAlphaSize int
+	Stereo                                                      bool
+	MultisampleBuffers, MultisampleSamples                      int
+	AcceleratedVisual                                           int // 1 to require, 0 to refuse, -1 for either
+	SwapControl                                                 int // 1 for vsync, 0 for none, -1 for the driver default
+}
+
+// Returns the configuration SDL uses when no attribute is set.
+func DefaultGLConfig() *GLConfig {
+	return &GLConfig{
+		RedSize:           3,
+		GreenSize:         3,
+		BlueSize:          2,
+		DoubleBuffer:      true,
+		DepthSize:         16,
+		AcceleratedVisual: -1,
+		SwapControl:       -1,
+	}
+}
+
+func (c *GLConfig) attributes() []struct {
+	attr  GLAttr
+	value *int
+} {
+	return []struct {
+		attr  GLAttr
+		value *int
+	}{
+		{GL_RED_SIZE, &c.RedSize},
+		{GL_GREEN_SIZE, &c.GreenSize},
+		{GL_BLUE_SIZE, &c.BlueSize},
+		{GL_ALPHA_SIZE, &c.AlphaSize},
+		{GL_BUFFER_SIZE, &c.BufferSize},
+		{GL_DEPTH_SIZE, &c.DepthSize},
+		{GL_STENCIL_SIZE, &c.StencilSize},
+		{GL_ACCUM_RED_SIZE, &c.AccumRedSize},
+		{GL_ACCUM_GREEN_SIZE, &c.AccumGreenSize},
+		{GL_ACCUM_BLUE_SIZE, &c.AccumBlueSize},
+		{GL_ACCUM_ALPHA_SIZE, &c.AccumAlphaSize},
+		{GL_MULTISAMPLEBUFFERS, &c.MultisampleBuffers},
+		{GL_MULTISAMPLESAMPLES, &c.MultisampleSamples},
+		{GL_ACCELERATED_VISUAL, &c.AcceleratedVisual},
+		{GL_SWAP_CONTROL, &c.SwapControl},
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Sets all attributes with GL_SetAttribute. They take effect on the next
+// call to SetVideoMode with the OPENGL flag. Returns 0 if successful or -1
+// if there was an error.
+func (c *GLConfig) Apply() int {
+	for _, a := range c.attributes() {
+		if GL_SetAttribute(a.attr, *a.value) != 0 {
+			return -1
+		}
+	}
+	if GL_SetAttribute(GL_DOUBLEBUFFER, boolToInt(c.DoubleBuffer)) != 0 {
+		return -1
+	}
+	if GL_SetAttribute(GL_STEREO, boolToInt(c.Stereo)) != 0 {
+		return -1
+	}
+	return 0
+}
+
+// Applies the configuration and sets an OpenGL video mode. The OPENGL flag
+// is added to flags. Returns nil on error.
+func (c *GLConfig) SetVideoMode(w, h, bpp int, flags uint32) *Surface {
+	if c.Apply() != 0 {
+		return nil
+	}
+	return SetVideoMode(w, h, bpp, flags|OPENGL)
+}
+
+// Returns the attributes of the current OpenGL display, as obtained with
+// GL_GetAttribute, or nil if there was an error. The values can differ from
+// the requested ones, for example a larger depth buffer. AcceleratedVisual
+// and SwapControl are -1 if the driver cannot report them.
+func GL_GetConfig() *GLConfig {
+	c := new(GLConfig)
+	for _, a := range c.attributes() {
+		if GL_GetAttribute(a.attr, a.value) != 0 {
+			if (a.attr == GL_ACCELERATED_VISUAL) || (a.attr == GL_SWAP_CONTROL) {
+				*a.value = -1
+				continue
+			}
+			return nil
+		}
+	}
+
+	var doubleBuffer, stereo int
+	if GL_GetAttribute(GL_DOUBLEBUFFER, &doubleBuffer) != 0 {
+		return nil
+	}
+	if GL_GetAttribute(GL_STEREO, &stereo) != 0 {
+		return nil
+	}
+	c.DoubleBuffer = doubleBuffer != 0
+	c.Stereo = stereo != 0
+
+	return c
+}
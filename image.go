@@ -0,0 +1,353 @@
+package sdl
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"unsafe"
+)
+
+// Surface implements image.Image and draw.Image, so the standard image
+// packages can operate on SDL surfaces directly.
+//
+// At and Set access the pixels without any locking. Surfaces which need
+// locking (such as hardware surfaces) must be locked with Surface.Lock
+// around the access.
+var _ draw.Image = (*Surface)(nil)
+
+// True if the host stores multi-byte pixels with the least significant byte first.
+var nativeLittleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// Common 32-bit layouts which have a fast path in At and Set.
+const (
+	layoutGeneric = iota
+	layoutRGBA8888
+	layoutARGB8888
+	layoutABGR8888
+)
+
+func (f *PixelFormat) layout() int {
+	if f.BytesPerPixel != 4 {
+		return layoutGeneric
+	}
+
+	switch {
+	case f.Rmask == 0xff000000 && f.Gmask == 0x00ff0000 && f.Bmask == 0x0000ff00 && f.Amask == 0x000000ff:
+		return layoutRGBA8888
+	case f.Amask == 0xff000000 && f.Rmask == 0x00ff0000 && f.Gmask == 0x0000ff00 && f.Bmask == 0x000000ff:
+		return layoutARGB8888
+	case f.Amask == 0xff000000 && f.Bmask == 0x00ff0000 && f.Gmask == 0x0000ff00 && f.Rmask == 0x000000ff:
+		return layoutABGR8888
+	}
+
+	return layoutGeneric
+}
+
+// Returns true if the pixels of the format are indexes into a palette.
+func (f *PixelFormat) palettized() bool {
+	return f.Palette != nil && f.BytesPerPixel == 1
+}
+
+// Returns the i-th color of the palette.
+func (p *Palette) color(i int) Color {
+	return *(*Color)(unsafe.Pointer(uintptr(unsafe.Pointer(p.Colors)) + uintptr(i)*unsafe.Sizeof(Color{})))
+}
+
+// Returns the index of the palette color closest to r, g, b.
+func (p *Palette) nearest(r, g, b uint8) uint32 {
+	best, bestDist := 0, -1
+	for i := 0; i < int(p.Ncolors); i++ {
+		c := p.color(i)
+		dr, dg, db := int(c.R)-int(r), int(c.G)-int(g), int(c.B)-int(b)
+		dist := dr*dr + dg*dg + db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+			if dist == 0 {
+				break
+			}
+		}
+	}
+	return uint32(best)
+}
+
+// Expands a color component with 'loss' missing low bits to the full 8-bit range.
+func expand(v uint32, loss uint8) uint8 {
+	if loss >= 8 {
+		return 0
+	}
+	bits := 8 - loss
+	v <<= loss
+	for shift := bits; shift < 8; shift += bits {
+		v |= v >> shift
+	}
+	return uint8(v)
+}
+
+// Returns the address of the pixel at x, y.
+func (s *Surface) pixelAddr(x, y int) unsafe.Pointer {
+	return unsafe.Pointer(uintptr(s.Pixels) + uintptr(y*int(s.Pitch)+x*int(s.Format.BytesPerPixel)))
+}
+
+// Reads the raw pixel value at x, y. The coordinates are not checked.
+func (s *Surface) getPixel(x, y int) uint32 {
+	p := s.pixelAddr(x, y)
+
+	switch s.Format.BytesPerPixel {
+	case 1:
+		return uint32(*(*uint8)(p))
+	case 2:
+		return uint32(*(*uint16)(p))
+	case 3:
+		b := (*[3]byte)(p)
+		if nativeLittleEndian {
+			return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+		}
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+	case 4:
+		return *(*uint32)(p)
+	}
+
+	return 0
+}
+
+// Writes the raw pixel value at x, y. The coordinates are not checked.
+func (s *Surface) setPixel(x, y int, pixel uint32) {
+	p := s.pixelAddr(x, y)
+
+	switch s.Format.BytesPerPixel {
+	case 1:
+		*(*uint8)(p) = uint8(pixel)
+	case 2:
+		*(*uint16)(p) = uint16(pixel)
+	case 3:
+		b := (*[3]byte)(p)
+		if nativeLittleEndian {
+			b[0], b[1], b[2] = byte(pixel), byte(pixel>>8), byte(pixel>>16)
+		} else {
+			b[0], b[1], b[2] = byte(pixel>>16), byte(pixel>>8), byte(pixel)
+		}
+	case 4:
+		*(*uint32)(p) = pixel
+	}
+}
+
+// Converts a raw pixel value of the surface to a color. Pixels matching
+// the colorkey of the surface are fully transparent.
+func (s *Surface) pixelColor(pixel uint32) color.NRGBA {
+	f := s.Format
+
+	var c color.NRGBA
+	switch f.layout() {
+	case layoutRGBA8888:
+		c = color.NRGBA{uint8(pixel >> 24), uint8(pixel >> 16), uint8(pixel >> 8), uint8(pixel)}
+	case layoutARGB8888:
+		c = color.NRGBA{uint8(pixel >> 16), uint8(pixel >> 8), uint8(pixel), uint8(pixel >> 24)}
+	case layoutABGR8888:
+		c = color.NRGBA{uint8(pixel), uint8(pixel >> 8), uint8(pixel >> 16), uint8(pixel >> 24)}
+	default:
+		if f.palettized() {
+			if int(pixel) < int(f.Palette.Ncolors) {
+				pc := f.Palette.color(int(pixel))
+				c = color.NRGBA{pc.R, pc.G, pc.B, 0xff}
+			} else {
+				c = color.NRGBA{0, 0, 0, 0xff}
+			}
+		} else {
+			c.R = expand((pixel&f.Rmask)>>f.Rshift, f.Rloss)
+			c.G = expand((pixel&f.Gmask)>>f.Gshift, f.Gloss)
+			c.B = expand((pixel&f.Bmask)>>f.Bshift, f.Bloss)
+			if f.Amask != 0 {
+				c.A = expand((pixel&f.Amask)>>f.Ashift, f.Aloss)
+			} else {
+				c.A = 0xff
+			}
+		}
+	}
+
+	if (s.Flags&SRCCOLORKEY != 0) && (pixel == f.Colorkey) {
+		c.A = 0
+	}
+
+	return c
+}
+
+// Converts a color to a raw pixel value of the surface. Fully transparent
+// colors map to the colorkey if the surface has no alpha channel.
+func (s *Surface) colorPixel(c color.NRGBA) uint32 {
+	f := s.Format
+
+	if (c.A == 0) && (f.Amask == 0) && (s.Flags&SRCCOLORKEY != 0) {
+		return f.Colorkey
+	}
+
+	switch f.layout() {
+	case layoutRGBA8888:
+		return uint32(c.R)<<24 | uint32(c.G)<<16 | uint32(c.B)<<8 | uint32(c.A)
+	case layoutARGB8888:
+		return uint32(c.A)<<24 | uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+	case layoutABGR8888:
+		return uint32(c.A)<<24 | uint32(c.B)<<16 | uint32(c.G)<<8 | uint32(c.R)
+	}
+
+	if f.palettized() {
+		return f.Palette.nearest(c.R, c.G, c.B)
+	}
+
+	pixel := (uint32(c.R>>f.Rloss) << f.Rshift) & f.Rmask
+	pixel |= (uint32(c.G>>f.Gloss) << f.Gshift) & f.Gmask
+	pixel |= (uint32(c.B>>f.Bloss) << f.Bshift) & f.Bmask
+	pixel |= (uint32(c.A>>f.Aloss) << f.Ashift) & f.Amask
+	return pixel
+}
+
+// Returns the bounds of the surface, which always start at 0, 0.
+func (s *Surface) Bounds() image.Rectangle {
+	return image.Rect(0, 0, int(s.W), int(s.H))
+}
+
+// Returns the color model of the surface. Palettized surfaces return
+// their palette as a color.Palette, all other surfaces color.NRGBAModel.
+func (s *Surface) ColorModel() color.Model {
+	f := s.Format
+	if (f == nil) || !f.palettized() {
+		return color.NRGBAModel
+	}
+
+	p := make(color.Palette, f.Palette.Ncolors)
+	for i := range p {
+		p[i] = s.pixelColor(uint32(i))
+	}
+	return p
+}
+
+// Returns the color of the pixel at x, y.
+// Coordinates outside of the surface return a transparent color.
+func (s *Surface) At(x, y int) color.Color {
+	if x < 0 || y < 0 || x >= int(s.W) || y >= int(s.H) {
+		return color.NRGBA{}
+	}
+	return s.pixelColor(s.getPixel(x, y))
+}
+
+// Sets the color of the pixel at x, y.
+// Coordinates outside of the surface are ignored.
+func (s *Surface) Set(x, y int, c color.Color) {
+	if x < 0 || y < 0 || x >= int(s.W) || y >= int(s.H) {
+		return
+	}
+	s.setPixel(x, y, s.colorPixel(color.NRGBAModel.Convert(c).(color.NRGBA)))
+}
+
+// Returns the masks of a 32-bit format which stores the components
+// in R, G, B, A byte order, as used by image.RGBA and image.NRGBA.
+func byteOrderRGBAMasks() (r, g, b, a uint32) {
+	if nativeLittleEndian {
+		return 0x000000ff, 0x0000ff00, 0x00ff0000, 0xff000000
+	}
+	return 0xff000000, 0x00ff0000, 0x0000ff00, 0x000000ff
+}
+
+// Creates a Surface from an image.
+//
+// The pixels of *image.NRGBA, *image.Gray and *image.Paletted images are
+// shared with the returned surface and are not copied. The same is true for
+// *image.RGBA images which are opaque; RGBA images with translucent pixels
+// are converted because SDL expects colors which are not premultiplied.
+// Paletted and gray images create 8-bit surfaces with a palette, and a fully
+// transparent palette entry becomes the colorkey of the surface. Palettes
+// with partially transparent colors cannot be expressed this way, so such
+// images are converted.
+// All other images are converted to a 32-bit surface with an alpha channel.
+//
+// Returns nil on error.
+func NewSurfaceFromImage(img image.Image) *Surface {
+	r := img.Bounds()
+
+	switch m := img.(type) {
+	case *image.NRGBA:
+		return newSurfaceFromRGBA(m.Pix[m.PixOffset(r.Min.X, r.Min.Y):], r.Dx(), r.Dy(), m.Stride)
+
+	case *image.RGBA:
+		if m.Opaque() {
+			return newSurfaceFromRGBA(m.Pix[m.PixOffset(r.Min.X, r.Min.Y):], r.Dx(), r.Dy(), m.Stride)
+		}
+
+	case *image.Paletted:
+		if translucent(m.Palette) {
+			break
+		}
+		s := CreateRGBSurfaceFrom(m.Pix[m.PixOffset(r.Min.X, r.Min.Y):], r.Dx(), r.Dy(), 8, m.Stride, 0, 0, 0, 0)
+		if s != nil {
+			s.setImagePalette(m.Palette)
+		}
+		return s
+
+	case *image.Gray:
+		s := CreateRGBSurfaceFrom(m.Pix[m.PixOffset(r.Min.X, r.Min.Y):], r.Dx(), r.Dy(), 8, m.Stride, 0, 0, 0, 0)
+		if s != nil {
+			gray := make(color.Palette, 256)
+			for i := range gray {
+				gray[i] = color.Gray{uint8(i)}
+			}
+			s.setImagePalette(gray)
+		}
+		return s
+	}
+
+	// Convert anything else, including translucent RGBA images
+	m := image.NewNRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(m, m.Bounds(), img, r.Min, draw.Src)
+	return newSurfaceFromRGBA(m.Pix, r.Dx(), r.Dy(), m.Stride)
+}
+
+// Creates a 32-bit surface sharing pixels stored in R, G, B, A byte order.
+func newSurfaceFromRGBA(pix []uint8, w, h, stride int) *Surface {
+	rmask, gmask, bmask, amask := byteOrderRGBAMasks()
+	return CreateRGBSurfaceFrom(pix, w, h, 32, stride, rmask, gmask, bmask, amask)
+}
+
+// Returns true if the palette has partially transparent colors
+// or more than one fully transparent color.
+func translucent(p color.Palette) bool {
+	transparent := 0
+	for _, c := range p {
+		_, _, _, a := c.RGBA()
+		switch {
+		case a == 0:
+			transparent++
+		case a != 0xffff:
+			return true
+		}
+	}
+	return transparent > 1
+}
+
+// Loads a color.Palette into the palette of an 8-bit surface.
+// The first fully transparent color becomes the colorkey.
+func (s *Surface) setImagePalette(p color.Palette) {
+	if len(p) > 256 {
+		p = p[:256]
+	}
+	if len(p) == 0 {
+		return
+	}
+
+	colors := make([]Color, len(p))
+	key := -1
+	for i, c := range p {
+		nc := color.NRGBAModel.Convert(c).(color.NRGBA)
+		colors[i] = Color{R: nc.R, G: nc.G, B: nc.B}
+		if (nc.A == 0) && (key < 0) {
+			key = i
+		}
+	}
+
+	s.SetColors(colors, 0)
+
+	if key >= 0 {
+		s.SetColorKey(SRCCOLORKEY, uint32(key))
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Package image provides an interface to the SDL_image library, which loads
+PNG, JPEG, GIF, TGA, PCX, XPM, TIFF, BMP and other image formats into SDL
+surfaces.
+
+Alpha channels and transparent colors of the images are preserved: the
+loaded surfaces have an alpha channel or a colorkey set as appropriate.
+Like the core package, calls into SDL_image are serialized with
+sdl.GlobalMutex and run on the Threadbound set with sdl.SetThreadbound.
+*/
+package image
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL_image -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL_image.h>
+//
+// static const char *detectFormat(SDL_RWops *src) {
+// 	if (IMG_isPNG(src)) return "PNG";
+// 	if (IMG_isJPG(src)) return "JPG";
+// 	if (IMG_isGIF(src)) return "GIF";
+// 	if (IMG_isBMP(src)) return "BMP";
+// 	if (IMG_isPCX(src)) return "PCX";
+// 	if (IMG_isXPM(src)) return "XPM";
+// 	if (IMG_isTIF(src)) return "TIF";
+// 	if (IMG_isLBM(src)) return "LBM";
+// 	if (IMG_isPNM(src)) return "PNM";
+// 	if (IMG_isXCF(src)) return "XCF";
+// 	if (IMG_isXV(src)) return "XV";
+// 	if (IMG_isICO(src)) return "ICO";
+// 	if (IMG_isCUR(src)) return "CUR";
+// 	if (IMG_isWEBP(src)) return "WEBP";
+// 	return NULL;
+// }
+import "C"
+import (
+	"sdl"
+	"unsafe"
+)
+
+// Flags for Init, which load support for the formats that need external
+// libraries in advance.
+const (
+	INIT_JPG  = C.IMG_INIT_JPG
+	INIT_PNG  = C.IMG_INIT_PNG
+	INIT_TIF  = C.IMG_INIT_TIF
+	INIT_WEBP = C.IMG_INIT_WEBP
+)
+
+// Loads the libraries for the given formats. Returns the flags of the
+// formats which are now available. Calling Init is optional; without it,
+// the libraries are loaded when they are first needed.
+func Init(flags int) int {
+	var status int
+	run(func() {
+		status = int(C.IMG_Init(C.int(flags)))
+	})
+	return status
+}
+
+// Unloads the libraries loaded by Init.
+func Quit() {
+	run(func() {
+		C.IMG_Quit()
+	})
+}
+
+// Runs f on the Threadbound while holding the global mutex.
+func run(f func()) {
+	sdl.RunThreadbound(func() {
+		sdl.GlobalMutex.Lock()
+		f()
+		sdl.GlobalMutex.Unlock()
+	})
+}
+
+// Returns the C RWops of rw. Reports an error and returns nil if rw is nil
+// or closed.
+func cRWops(function string, rw *sdl.RWops) *C.SDL_RWops {
+	if rw == nil {
+		sdl.SetError("image: " + function + ": the RWops is nil")
+		return nil
+	}
+	crw := (*C.SDL_RWops)(rw.CRWops())
+	if crw == nil {
+		sdl.SetError("image: " + function + ": the RWops has been closed")
+	}
+	return crw
+}
+
+// Loads an image file into a new surface. The format is detected from the
+// contents, or else from the file extension (needed for TGA files).
+// Returns nil on error.
+func Load(file string) *sdl.Surface {
+	cfile := C.CString(file)
+	var surface *C.SDL_Surface
+	run(func() {
+		surface = C.IMG_Load(cfile)
+	})
+	C.free(unsafe.Pointer(cfile))
+	return sdl.WrapSurface(unsafe.Pointer(surface))
+}
+
+// Loads an image from an RWops into a new surface, detecting the format
+// from the contents. TGA images cannot be detected; use LoadTypedRW for them.
+// The RWops is not closed. Returns nil on error, or if rw is nil or closed.
+func LoadRW(rw *sdl.RWops) *sdl.Surface {
+	crw := cRWops("LoadRW", rw)
+	if crw == nil {
+		return nil
+	}
+
+	var surface *C.SDL_Surface
+	run(func() {
+		surface = C.IMG_Load_RW(crw, 0)
+	})
+	return sdl.WrapSurface(unsafe.Pointer(surface))
+}
+
+// Loads an image of the given format from an RWops into a new surface.
+// The format is a name as returned by Format, or "TGA". If the contents
+// can be detected as another format, that format is used instead.
+// The RWops is not closed. Returns nil on error, or if rw is nil or closed.
+func LoadTypedRW(rw *sdl.RWops, format string) *sdl.Surface {
+	crw := cRWops("LoadTypedRW", rw)
+	if crw == nil {
+		return nil
+	}
+
+	cformat := C.CString(format)
+	var surface *C.SDL_Surface
+	run(func() {
+		surface = C.IMG_LoadTyped_RW(crw, 0, cformat)
+	})
+	C.free(unsafe.Pointer(cformat))
+	return sdl.WrapSurface(unsafe.Pointer(surface))
+}
+
+// Loads an image from memory into a new surface, such as an asset embedded
+// in the program. Returns nil on error.
+func LoadBytes(data []byte) *sdl.Surface {
+	rw := sdl.RWFromMem(data)
+	if rw == nil {
+		return nil
+	}
+	defer rw.Close()
+	return LoadRW(rw)
+}
+
+// Detects the format of the image in the RWops from its contents, without
+// changing the read position. Returns one of "PNG", "JPG", "GIF", "BMP",
+// "PCX", "XPM", "TIF", "LBM", "PNM", "XCF", "XV", "ICO", "CUR" and "WEBP",
+// or "" if the format is unknown or rw is nil or closed.
+func Format(rw *sdl.RWops) string {
+	crw := cRWops("Format", rw)
+	if crw == nil {
+		return ""
+	}
+
+	var format string
+	run(func() {
+		if cformat := C.detectFormat(crw); cformat != nil {
+			format = C.GoString(cformat)
+		}
+	})
+	return format
+}
@@ -5,6 +5,15 @@ package sdl
 // #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
 //
 // #include <SDL/SDL.h>
+//
+// static void joystickState(SDL_Joystick *j, Sint16 *axes, int naxes, Uint8 *buttons, int nbuttons,
+//                           Uint8 *hats, int nhats, int *balls, int nballs) {
+// 	int i;
+// 	for (i = 0; i < naxes; i++) axes[i] = SDL_JoystickGetAxis(j, i);
+// 	for (i = 0; i < nbuttons; i++) buttons[i] = SDL_JoystickGetButton(j, i);
+// 	for (i = 0; i < nhats; i++) hats[i] = SDL_JoystickGetHat(j, i);
+// 	for (i = 0; i < nballs; i++) SDL_JoystickGetBall(j, i, &balls[2*i], &balls[2*i+1]);
+// }
 import "C"
 import "unsafe"
 
@@ -13,6 +22,9 @@ type Mod C.int
 type Key C.int
 type Joystick struct {
 	cJoystick *C.SDL_Joystick
+
+	// Number of axes, buttons, hats and balls, queried once at open
+	numAxes, numButtons, numHats, numBalls int
 }
 
 // Enables UNICODE translation.
@@ -96,6 +108,10 @@ func wrapJoystick(cJoystick *C.SDL_Joystick) *Joystick {
 	if cJoystick != nil {
 		var joystick Joystick
 		joystick.cJoystick = (*C.SDL_Joystick)(unsafe.Pointer(cJoystick))
+		joystick.numAxes = int(C.SDL_JoystickNumAxes(cJoystick))
+		joystick.numButtons = int(C.SDL_JoystickNumButtons(cJoystick))
+		joystick.numHats = int(C.SDL_JoystickNumHats(cJoystick))
+		joystick.numBalls = int(C.SDL_JoystickNumBalls(cJoystick))
 		j = &joystick
 	} else {
 		j = nil
@@ -200,3 +216,106 @@ func (joystick *Joystick) GetBall(ball int, dx, dy *int) int {
 func (joystick *Joystick) GetAxis(axis int) int16 {
 	return int16(C.SDL_JoystickGetAxis(joystick.cJoystick, C.int(axis)))
 }
+
+// Relative motion of a trackball since the previous poll.
+type BallDelta struct {
+	Dx, Dy int
+}
+
+// A snapshot of the state of all controls on a joystick.
+type JoystickState struct {
+	Which   uint8       // Device index of the joystick
+	Axes    []int16     // Axis positions, ranging from -32768 to 32767
+	Buttons []uint8     // Button states, 1 if pressed and 0 if not
+	Hats    []uint8     // POV hat positions, a combination of HAT_* values
+	Balls   []BallDelta // Trackball motion since the previous poll
+}
+
+// Gets the state of all axes, buttons, hats and balls of a joystick with
+// a single call into SDL. Call JoystickUpdate first if joystick events are
+// disabled with JoystickEventState.
+//
+// Reading the state consumes the pending ball motion, just like GetBall.
+func (joystick *Joystick) State() *JoystickState {
+	state := &JoystickState{
+		Which:   uint8(C.SDL_JoystickIndex(joystick.cJoystick)),
+		Axes:    make([]int16, joystick.numAxes),
+		Buttons: make([]uint8, joystick.numButtons),
+		Hats:    make([]uint8, joystick.numHats),
+		Balls:   make([]BallDelta, joystick.numBalls),
+	}
+
+	balls := make([]C.int, 2*joystick.numBalls)
+
+	var axes *C.Sint16
+	var buttons, hats *C.Uint8
+	var cballs *C.int
+	if len(state.Axes) > 0 {
+		axes = (*C.Sint16)(unsafe.Pointer(&state.Axes[0]))
+	}
+	if len(state.Buttons) > 0 {
+		buttons = (*C.Uint8)(unsafe.Pointer(&state.Buttons[0]))
+	}
+	if len(state.Hats) > 0 {
+		hats = (*C.Uint8)(unsafe.Pointer(&state.Hats[0]))
+	}
+	if len(balls) > 0 {
+		cballs = &balls[0]
+	}
+
+	C.joystickState(joystick.cJoystick,
+		axes, C.int(len(state.Axes)),
+		buttons, C.int(len(state.Buttons)),
+		hats, C.int(len(state.Hats)),
+		cballs, C.int(len(state.Balls)))
+
+	for i := range state.Balls {
+		state.Balls[i] = BallDelta{int(balls[2*i]), int(balls[2*i+1])}
+	}
+
+	return state
+}
+
+// Compares the state with an older snapshot of the same joystick and returns
+// the events that describe the change, in the same form as they are delivered
+// by the Events channel: JoyAxisEvent, JoyButtonEvent, JoyHatEvent and
+// JoyBallEvent. If old is nil, every control is reported as changed.
+func (state *JoystickState) Diff(old *JoystickState) []interface{} {
+	if old == nil {
+		old = &JoystickState{}
+	}
+
+	var events []interface{}
+
+	for i, value := range state.Axes {
+		if i >= len(old.Axes) || old.Axes[i] != value {
+			events = append(events, JoyAxisEvent{Type: JOYAXISMOTION, Which: state.Which, Axis: uint8(i), Value: value})
+		}
+	}
+
+	for i, value := range state.Buttons {
+		if i >= len(old.Buttons) || old.Buttons[i] != value {
+			e := JoyButtonEvent{Type: JOYBUTTONUP, Which: state.Which, Button: uint8(i), State: RELEASED}
+			if value != 0 {
+				e.Type = JOYBUTTONDOWN
+				e.State = PRESSED
+			}
+			events = append(events, e)
+		}
+	}
+
+	for i, value := range state.Hats {
+		if i >= len(old.Hats) || old.Hats[i] != value {
+			events = append(events, JoyHatEvent{Type: JOYHATMOTION, Which: state.Which, Hat: uint8(i), Value: value})
+		}
+	}
+
+	// Ball deltas are already relative to the previous poll
+	for i, delta := range state.Balls {
+		if delta.Dx != 0 || delta.Dy != 0 {
+			events = append(events, JoyBallEvent{Type: JOYBALLMOTION, Which: state.Which, Ball: uint8(i), Xrel: int16(delta.Dx), Yrel: int16(delta.Dy)})
+		}
+	}
+
+	return events
+}
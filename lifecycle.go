@@ -0,0 +1,131 @@
+package sdl
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// Where and how large a tracked surface was created
+type allocation struct {
+	w, h  int32
+	stack []uintptr
+}
+
+// Debugging state for surface lifecycles, see SetSurfaceFinalizers and
+// SetLeakReport. The live surfaces are keyed by their C pointer, so that
+// tracking does not keep the Go surfaces reachable.
+var surfaceDebug struct {
+	sync.Mutex
+	finalizers bool
+	leakReport bool
+	live       map[unsafe.Pointer]*allocation
+}
+
+// Enables or disables a finalizer for surfaces created afterwards. If such
+// a surface becomes unreachable without having been freed, the finalizer
+// frees it and logs where it was created.
+//
+// This is meant for finding leaks while debugging: finalizers run at an
+// unpredictable time, and recording where surfaces are created is slow.
+// The video surface never gets a finalizer.
+func SetSurfaceFinalizers(enable bool) {
+	surfaceDebug.Lock()
+	surfaceDebug.finalizers = enable
+	surfaceDebug.Unlock()
+}
+
+// Enables or disables the leak report for surfaces created afterwards.
+// Quit logs the surfaces which have not been freed, and where they were
+// created. Recording where surfaces are created is slow, so this is meant
+// for debugging.
+func SetLeakReport(enable bool) {
+	surfaceDebug.Lock()
+	surfaceDebug.leakReport = enable
+	surfaceDebug.Unlock()
+}
+
+// Records a newly created surface if debugging is enabled.
+func track(s *Surface) {
+	surfaceDebug.Lock()
+	defer surfaceDebug.Unlock()
+
+	if !surfaceDebug.finalizers && !surfaceDebug.leakReport {
+		return
+	}
+
+	stack := make([]uintptr, 32)
+	// Skip runtime.Callers, track and wrap
+	stack = stack[:runtime.Callers(3, stack)]
+
+	if surfaceDebug.live == nil {
+		surfaceDebug.live = make(map[unsafe.Pointer]*allocation)
+	}
+	surfaceDebug.live[unsafe.Pointer(s.cSurface)] = &allocation{s.W, s.H, stack}
+
+	if surfaceDebug.finalizers {
+		runtime.SetFinalizer(s, finalizeSurface)
+	}
+}
+
+// Forgets a surface which is being freed.
+func untrack(s *Surface) {
+	surfaceDebug.Lock()
+	delete(surfaceDebug.live, unsafe.Pointer(s.cSurface))
+	surfaceDebug.Unlock()
+}
+
+// Frees a surface which has become unreachable without having been freed.
+func finalizeSurface(s *Surface) {
+	if s.cSurface == nil {
+		return
+	}
+
+	surfaceDebug.Lock()
+	a := surfaceDebug.live[unsafe.Pointer(s.cSurface)]
+	surfaceDebug.Unlock()
+
+	// Surfaces which were live at Quit are no longer tracked, and cannot be
+	// freed safely after SDL has been shut down
+	if a == nil {
+		return
+	}
+
+	log.Printf("sdl: freeing a %dx%d surface which was not freed, created at:\n%s", a.w, a.h, formatStack(a.stack))
+	s.Free()
+}
+
+// Logs the surfaces which have not been freed, and stops tracking them.
+// Called by Quit.
+func reportLeaks() {
+	surfaceDebug.Lock()
+	live := surfaceDebug.live
+	surfaceDebug.live = nil
+	report := surfaceDebug.leakReport
+	surfaceDebug.Unlock()
+
+	if !report || (len(live) == 0) {
+		return
+	}
+
+	log.Printf("sdl: %d surfaces were not freed", len(live))
+	for _, a := range live {
+		log.Printf("sdl: %dx%d surface created at:\n%s", a.w, a.h, formatStack(a.stack))
+	}
+}
+
+func formatStack(stack []uintptr) string {
+	var buf bytes.Buffer
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&buf, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return buf.String()
+}
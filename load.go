@@ -0,0 +1,128 @@
+package sdl
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL.h>
+//
+// static SDL_Surface *loadBMP(const char *file) {
+// 	return SDL_LoadBMP_RW(SDL_RWFromFile(file, "rb"), 1);
+// }
+// static SDL_Surface *loadBMPFromMem(const void *mem, int size) {
+// 	return SDL_LoadBMP_RW(SDL_RWFromConstMem(mem, size), 1);
+// }
+// static int saveBMP(SDL_Surface *surface, const char *file) {
+// 	return SDL_SaveBMP_RW(surface, SDL_RWFromFile(file, "wb"), 1);
+// }
+import "C"
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"unsafe"
+)
+
+// Loads an image file into a new Surface. The format is detected from the
+// contents of the file; BMP, PNG, GIF and JPEG images are supported.
+// Alpha channels and transparent palette colors (as colorkey) are preserved.
+//
+// Returns nil on error, the error can be retrieved with GetError.
+func Load(file string) *Surface {
+	f, err := os.Open(file)
+	if err != nil {
+		SetError(err.Error())
+		return nil
+	}
+	defer f.Close()
+
+	return LoadFrom(f)
+}
+
+// Loads an image from a reader into a new Surface.
+// See func Load for the supported formats.
+//
+// Returns nil on error, the error can be retrieved with GetError.
+func LoadFrom(r io.Reader) *Surface {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		SetError(err.Error())
+		return nil
+	}
+
+	// BMP files are left to SDL, which also handles its less common variants
+	if bytes.HasPrefix(data, []byte("BM")) {
+		return wrap(C.loadBMPFromMem(unsafe.Pointer(&data[0]), C.int(len(data))))
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		SetError(err.Error())
+		return nil
+	}
+
+	return NewSurfaceFromImage(img)
+}
+
+// Loads a Windows BMP file into a new Surface.
+// Returns nil on error.
+func LoadBMP(file string) *Surface {
+	cfile := C.CString(file)
+	s := wrap(C.loadBMP(cfile))
+	C.free(unsafe.Pointer(cfile))
+	return s
+}
+
+// Saves the surface as a Windows BMP file.
+// Returns 0 if successful or -1 if there was an error.
+func (s *Surface) SaveBMP(file string) int {
+	cfile := C.CString(file)
+
+	s.mutex.RLock()
+	status := -1
+	if !s.freed("Surface.SaveBMP") {
+		status = int(C.saveBMP(s.cSurface, cfile))
+	}
+	s.mutex.RUnlock()
+
+	C.free(unsafe.Pointer(cfile))
+	return status
+}
+
+// Saves the surface as a PNG file. Palettized surfaces are saved as
+// paletted PNG images, all other surfaces as 8-bit RGBA.
+// Returns 0 if successful or -1 if there was an error.
+func (s *Surface) SavePNG(file string) int {
+	f, err := os.Create(file)
+	if err != nil {
+		SetError(err.Error())
+		return -1
+	}
+
+	err = s.WritePNG(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		SetError(err.Error())
+		return -1
+	}
+
+	return 0
+}
+
+// Encodes the surface as a PNG image and writes it to w.
+func (s *Surface) WritePNG(w io.Writer) error {
+	if s.Lock() != 0 {
+		return errors.New(GetError())
+	}
+	defer s.Unlock()
+
+	return png.Encode(w, s)
+}
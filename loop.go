@@ -0,0 +1,214 @@
+package sdl
+
+import (
+	"sync"
+	"time"
+)
+
+// A game loop which updates the game at a fixed rate and renders it as often
+// as possible, or at most MaxFPS times per second.
+//
+// Each frame, the pending events are passed to Event, Update is called as
+// many times as needed to catch up with the time that passed, and Render is
+// called once. If updating takes too long to catch up, at most MaxUpdates
+// updates are made per frame and the rest of the time is dropped, so that
+// the game slows down instead of freezing.
+type Loop struct {
+	// Called for each event received from Events, once per frame before
+	// updating. May be nil.
+	Event func(event interface{})
+
+	// Called at the update rate with the duration of an update. May be nil.
+	Update func(step time.Duration)
+
+	// Called once per frame. alpha is the time since the last update as a
+	// fraction of the update step, from 0 to 1, for interpolating between
+	// the previous and the current state of the game. May be nil.
+	Render func(alpha float64)
+
+	MaxFPS     int // The frame rate cap; 0 for none
+	MaxUpdates int // The maximum number of updates per frame; 0 for 5
+
+	mutex   sync.Mutex
+	step    time.Duration
+	running bool
+	stats   LoopStats
+	window  loopWindow
+}
+
+// Statistics of a Loop, measured over the last second.
+type LoopStats struct {
+	FPS          float64       // Frames per second
+	UPS          float64       // Updates per second
+	FrameTime    time.Duration // The average time spent per frame, not counting the wait for the frame rate cap
+	MaxFrameTime time.Duration // The longest time spent on a frame
+	Frames       uint64        // The number of frames since Run was called
+	Dropped      uint64        // The number of updates dropped since Run was called
+}
+
+// The measurements of the current second
+type loopWindow struct {
+	start           time.Time
+	frames, updates int
+	work, maxWork   time.Duration
+	totalFrames     uint64
+	totalDropped    uint64
+}
+
+// Creates a loop which updates the given number of times per second.
+func NewLoop(updateRate int) *Loop {
+	l := new(Loop)
+	l.SetUpdateRate(updateRate)
+	return l
+}
+
+// Sets the number of updates per second. It may be called at any time,
+// also while the loop is running. Rates of 0 or less are ignored.
+func (l *Loop) SetUpdateRate(updateRate int) {
+	if updateRate <= 0 {
+		return
+	}
+	l.mutex.Lock()
+	l.step = time.Second / time.Duration(updateRate)
+	l.mutex.Unlock()
+}
+
+// Returns the number of updates per second.
+func (l *Loop) UpdateRate() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.step <= 0 {
+		return 0
+	}
+	return int(time.Second / l.step)
+}
+
+// Returns the statistics of the loop.
+func (l *Loop) Stats() LoopStats {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.stats
+}
+
+// Makes Run return after the current frame. It may be called from the
+// callbacks or from another goroutine.
+func (l *Loop) Stop() {
+	l.mutex.Lock()
+	l.running = false
+	l.mutex.Unlock()
+}
+
+func (l *Loop) isRunning() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.running
+}
+
+// Runs the loop until Stop is called.
+func (l *Loop) Run() {
+	l.mutex.Lock()
+	if l.step <= 0 {
+		l.step = time.Second / 60
+	}
+	l.running = true
+	l.stats = LoopStats{}
+	l.window = loopWindow{start: time.Now()}
+	l.mutex.Unlock()
+
+	previous := time.Now()
+	var lag time.Duration
+
+	for l.isRunning() {
+		start := time.Now()
+		lag += start.Sub(previous)
+		previous = start
+
+		l.drainEvents()
+
+		l.mutex.Lock()
+		step := l.step
+		l.mutex.Unlock()
+
+		maxUpdates := l.MaxUpdates
+		if maxUpdates <= 0 {
+			maxUpdates = 5
+		}
+
+		updates := 0
+		for (lag >= step) && (updates < maxUpdates) {
+			if l.Update != nil {
+				l.Update(step)
+			}
+			lag -= step
+			updates++
+		}
+
+		// Spiral of death protection
+		var dropped uint64
+		if lag >= step {
+			dropped = uint64(lag / step)
+			lag %= step
+		}
+
+		if l.Render != nil {
+			l.Render(float64(lag) / float64(step))
+		}
+
+		work := time.Since(start)
+		l.measure(work, updates, dropped)
+
+		if l.MaxFPS > 0 {
+			if wait := time.Second/time.Duration(l.MaxFPS) - work; wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+	}
+}
+
+// Passes the events which are pending to Event.
+func (l *Loop) drainEvents() {
+	for {
+		select {
+		case event := <-Events:
+			if l.Event != nil {
+				l.Event(event)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Adds a frame to the statistics, which are published once per second.
+func (l *Loop) measure(work time.Duration, updates int, dropped uint64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	w := &l.window
+	w.frames++
+	w.updates += updates
+	w.work += work
+	if work > w.maxWork {
+		w.maxWork = work
+	}
+	w.totalFrames++
+	w.totalDropped += dropped
+
+	l.stats.Frames = w.totalFrames
+	l.stats.Dropped = w.totalDropped
+
+	elapsed := time.Since(w.start)
+	if elapsed < time.Second {
+		return
+	}
+	l.stats.FPS = float64(w.frames) / elapsed.Seconds()
+	l.stats.UPS = float64(w.updates) / elapsed.Seconds()
+	l.stats.FrameTime = w.work / time.Duration(w.frames)
+	l.stats.MaxFrameTime = w.maxWork
+
+	*w = loopWindow{
+		start:        time.Now(),
+		totalFrames:  w.totalFrames,
+		totalDropped: w.totalDropped,
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * SDL_mixer calls its "finished" hooks on the SDL audio thread, which must
+ * not run Go code or block. The hooks only append an event to a small queue,
+ * and a goroutine waits for the events in callback_wait.
+ */
+
+#include "callback.h"
+#include <pthread.h>
+
+#define QUEUE_SIZE 256
+
+static pthread_mutex_t mutex = PTHREAD_MUTEX_INITIALIZER;
+static pthread_cond_t  cond  = PTHREAD_COND_INITIALIZER;
+
+static int queue[QUEUE_SIZE];
+static int head    = 0;
+static int count   = 0;
+static int stopped = 0;
+
+// Adds an event to the queue. Events are dropped if the queue is full,
+// so that the audio thread never waits for Go.
+static void push(int event) {
+	pthread_mutex_lock(&mutex);
+	if (count < QUEUE_SIZE) {
+		queue[(head + count) % QUEUE_SIZE] = event;
+		count++;
+		pthread_cond_signal(&cond);
+	}
+	pthread_mutex_unlock(&mutex);
+}
+
+static void channelFinished(int channel) {
+	push(channel);
+}
+
+static void musicFinished(void) {
+	push(CALLBACK_MUSIC_FINISHED);
+}
+
+void callback_install() {
+	pthread_mutex_lock(&mutex);
+	stopped = 0;
+	pthread_mutex_unlock(&mutex);
+
+	Mix_ChannelFinished(channelFinished);
+	Mix_HookMusicFinished(musicFinished);
+}
+
+// Blocks until an event is available and returns it,
+// or returns CALLBACK_STOPPED once callback_stop has been called.
+int callback_wait() {
+	int event;
+
+	pthread_mutex_lock(&mutex);
+	while ((count == 0) && !stopped) {
+		pthread_cond_wait(&cond, &mutex);
+	}
+	if (count > 0) {
+		event = queue[head];
+		head = (head + 1) % QUEUE_SIZE;
+		count--;
+	} else {
+		event = CALLBACK_STOPPED;
+	}
+	pthread_mutex_unlock(&mutex);
+
+	return event;
+}
+
+// Uninstalls the hooks and wakes up callback_wait.
+void callback_stop() {
+	Mix_ChannelFinished(NULL);
+	Mix_HookMusicFinished(NULL);
+
+	pthread_mutex_lock(&mutex);
+	stopped = 1;
+	pthread_cond_broadcast(&cond);
+	pthread_mutex_unlock(&mutex);
+}
@@ -0,0 +1,12 @@
+#include <SDL/SDL_mixer.h>
+
+// Event value of a finished music stream; finished channels are reported
+// by their (non-negative) channel number
+#define CALLBACK_MUSIC_FINISHED -1
+
+// Returned by callback_wait after callback_stop
+#define CALLBACK_STOPPED -2
+
+extern void callback_install();
+extern int  callback_wait();
+extern void callback_stop();
@@ -0,0 +1,537 @@
+/*
+Package mixer provides an interface to the SDL_mixer library, which plays
+sound samples on multiple channels and a music stream at the same time.
+
+Samples (chunks) are loaded from WAV, AIFF, VOC, OGG and other files,
+and music from OGG, MP3, MOD, MIDI, FLAC and other files, depending on
+the formats supported by the installed SDL_mixer.
+
+Notifications about channels and music that finished playing are sent to
+the Events channel by a goroutine, never on the SDL audio thread.
+
+Every function holds sdl.GlobalMutex while it calls SDL_mixer, so the
+functions may be called from any goroutine. The mutex is not held while
+waiting for notifications, and the hooks which SDL_mixer calls on the
+audio thread never take it.
+*/
+package mixer
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL_mixer -lSDL
+// #cgo freebsd LDFLAGS: -lpthread
+// #cgo linux LDFLAGS: -lpthread
+// #cgo windows LDFLAGS: -lpthread -lwinmm -lgdi32 -ldxguid
+//
+// #include <stdlib.h>
+// #include <SDL/SDL_mixer.h>
+// #include "callback.h"
+//
+// static Mix_Chunk *loadWAV(const char *file) { return Mix_LoadWAV(file); }
+import "C"
+import (
+	"sdl"
+	"sync"
+	"unsafe"
+)
+
+// Flags for Init, which load support for the formats that need external
+// libraries in advance.
+const (
+	INIT_FLAC = C.MIX_INIT_FLAC
+	INIT_MOD  = C.MIX_INIT_MOD
+	INIT_MP3  = C.MIX_INIT_MP3
+	INIT_OGG  = C.MIX_INIT_OGG
+)
+
+// Default values for OpenAudio
+const (
+	DEFAULT_FREQUENCY = C.MIX_DEFAULT_FREQUENCY
+	DEFAULT_FORMAT    = C.MIX_DEFAULT_FORMAT
+	DEFAULT_CHANNELS  = C.MIX_DEFAULT_CHANNELS
+	DEFAULT_CHUNKSIZE = 1024
+)
+
+// The number of mixing channels allocated by OpenAudio
+const CHANNELS = C.MIX_CHANNELS
+
+// The maximum volume of channels, chunks and music
+const MAX_VOLUME = C.MIX_MAX_VOLUME
+
+// Fading status, as returned by FadingChannel and FadingMusic
+const (
+	NO_FADING  = C.MIX_NO_FADING
+	FADING_OUT = C.MIX_FADING_OUT
+	FADING_IN  = C.MIX_FADING_IN
+)
+
+// Sent to Events when a channel stops playing, because the chunk ended or
+// the channel was halted.
+type ChannelFinishedEvent struct {
+	Channel int
+}
+
+// Sent to Events when the music stops playing, because it ended or was
+// halted.
+type MusicFinishedEvent struct{}
+
+var events = make(chan interface{})
+
+// Notifications (ChannelFinishedEvent and MusicFinishedEvent) are sent to
+// this channel while the audio device is open. Up to 256 notifications are
+// kept while nobody is receiving from the channel; later ones are dropped.
+var Events <-chan interface{} = events
+
+// Guards forwarding, which is true while the forwarding goroutine runs
+var forwardMutex sync.Mutex
+var forwarding bool
+var forwardDone chan bool
+
+// Moves notifications from the C queue to the Events channel until
+// callback_stop is called.
+func forwardEvents(done chan bool) {
+	for {
+		event := int(C.callback_wait())
+		switch {
+		case event == C.CALLBACK_STOPPED:
+			done <- true
+			return
+		case event == C.CALLBACK_MUSIC_FINISHED:
+			events <- MusicFinishedEvent{}
+		default:
+			events <- ChannelFinishedEvent{event}
+		}
+	}
+}
+
+// Loads the libraries for the given formats. Returns the flags of the
+// formats which are now available. Calling Init is optional; without it,
+// the libraries are loaded when they are first needed.
+func Init(flags int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_Init(C.int(flags)))
+}
+
+// Unloads the libraries loaded by Init.
+func Quit() {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_Quit()
+}
+
+// Opens the audio device. The format is one of the audio.AUDIO_* values,
+// channels is 1 for mono or 2 for stereo, and chunksize is the number of
+// samples mixed at a time. Returns 0 if successful or -1 if there was an
+// error.
+func OpenAudio(frequency int, format uint16, channels, chunksize int) int {
+	sdl.GlobalMutex.Lock()
+	status := int(C.Mix_OpenAudio(C.int(frequency), C.Uint16(format), C.int(channels), C.int(chunksize)))
+	sdl.GlobalMutex.Unlock()
+
+	if status != 0 {
+		return status
+	}
+
+	forwardMutex.Lock()
+	if !forwarding {
+		sdl.GlobalMutex.Lock()
+		C.callback_install()
+		sdl.GlobalMutex.Unlock()
+		forwardDone = make(chan bool)
+		forwarding = true
+		go forwardEvents(forwardDone)
+	}
+	forwardMutex.Unlock()
+
+	return 0
+}
+
+// Closes the audio device. Calls must be balanced with calls to OpenAudio:
+// the device is closed by the last call to CloseAudio.
+//
+// Notifications which have not been received from Events yet are
+// dropped. CloseAudio must not be called from the goroutine which receives
+// from Events.
+func CloseAudio() {
+	sdl.GlobalMutex.Lock()
+	C.Mix_CloseAudio()
+	var frequency C.int
+	var format C.Uint16
+	var channels C.int
+	open := C.Mix_QuerySpec(&frequency, &format, &channels) != 0
+	sdl.GlobalMutex.Unlock()
+
+	if open {
+		return
+	}
+
+	forwardMutex.Lock()
+	if forwarding {
+		sdl.GlobalMutex.Lock()
+		C.callback_stop()
+		sdl.GlobalMutex.Unlock()
+		// Drop pending notifications until the goroutine has stopped
+	drain:
+		for {
+			select {
+			case <-events:
+			case <-forwardDone:
+				break drain
+			}
+		}
+		forwarding = false
+	}
+	forwardMutex.Unlock()
+}
+
+// Returns the actual parameters of the open audio device. The number of
+// times the device has been opened is returned in opened, or 0 if the
+// device is not open.
+func QuerySpec() (frequency int, format uint16, channels int, opened int) {
+	var cfrequency C.int
+	var cformat C.Uint16
+	var cchannels C.int
+	sdl.GlobalMutex.Lock()
+	opened = int(C.Mix_QuerySpec(&cfrequency, &cformat, &cchannels))
+	sdl.GlobalMutex.Unlock()
+	return int(cfrequency), uint16(cformat), int(cchannels), opened
+}
+
+// A sound sample which can be played on a channel.
+type Chunk struct {
+	cChunk *C.Mix_Chunk
+	mutex  sync.Mutex
+}
+
+func wrapChunk(cChunk *C.Mix_Chunk) *Chunk {
+	if cChunk == nil {
+		return nil
+	}
+	return &Chunk{cChunk: cChunk}
+}
+
+// Loads a sample from a file. The audio device must be open, because the
+// sample is converted to its format. Returns nil on error.
+func LoadWAV(file string) *Chunk {
+	cfile := C.CString(file)
+	defer C.free(unsafe.Pointer(cfile))
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return wrapChunk(C.loadWAV(cfile))
+}
+
+// Loads a sample from an RWops. The RWops is not closed.
+// Returns nil on error, or if rw is nil or closed.
+func LoadWAV_RW(rw *sdl.RWops) *Chunk {
+	crw := cRWops("LoadWAV_RW", rw)
+	if crw == nil {
+		return nil
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return wrapChunk(C.Mix_LoadWAV_RW(crw, 0))
+}
+
+// Returns the C RWops of rw. Reports an error and returns nil if rw is nil
+// or closed.
+func cRWops(function string, rw *sdl.RWops) *C.SDL_RWops {
+	if rw == nil {
+		sdl.SetError("mixer: " + function + ": the RWops is nil")
+		return nil
+	}
+	crw := (*C.SDL_RWops)(rw.CRWops())
+	if crw == nil {
+		sdl.SetError("mixer: " + function + ": the RWops has been closed")
+	}
+	return crw
+}
+
+// Loads a sample from memory, such as an asset embedded in the program.
+// Returns nil on error.
+func LoadWAVBytes(data []byte) *Chunk {
+	rw := sdl.RWFromMem(data)
+	if rw == nil {
+		return nil
+	}
+	defer rw.Close()
+	return LoadWAV_RW(rw)
+}
+
+// Frees the sample, halting the channels which play it. Freeing twice has
+// no effect.
+func (c *Chunk) Free() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.cChunk != nil {
+		sdl.GlobalMutex.Lock()
+		C.Mix_FreeChunk(c.cChunk)
+		sdl.GlobalMutex.Unlock()
+		c.cChunk = nil
+	}
+}
+
+// Sets the volume of the sample, from 0 to MAX_VOLUME, or only returns
+// it if volume is -1. Returns the previous volume, or -1 if the sample
+// has been freed.
+func (c *Chunk) Volume(volume int) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.cChunk == nil {
+		sdl.SetError("mixer: the chunk has been freed")
+		return -1
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_VolumeChunk(c.cChunk, C.int(volume)))
+}
+
+// Changes the number of mixing channels. Channels above the new number
+// are halted. Returns the number of allocated channels, or only returns
+// it if numchans is -1.
+func AllocateChannels(numchans int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_AllocateChannels(C.int(numchans)))
+}
+
+// Reserves the first num channels, so that PlayChannel with channel -1
+// does not use them. Returns the number of reserved channels.
+func ReserveChannels(num int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_ReserveChannels(C.int(num)))
+}
+
+// Plays a sample on a channel, or on the first free unreserved channel if
+// channel is -1. The sample is repeated loops times, or forever if loops
+// is -1. Returns the channel, or -1 if there was an error.
+func PlayChannel(channel int, chunk *Chunk, loops int) int {
+	return PlayChannelTimed(channel, chunk, loops, -1)
+}
+
+// Like PlayChannel, but stops playing after at most ticks milliseconds
+// (-1 for no limit).
+func PlayChannelTimed(channel int, chunk *Chunk, loops, ticks int) int {
+	chunk.mutex.Lock()
+	defer chunk.mutex.Unlock()
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_PlayChannelTimed(C.int(channel), chunk.cChunk, C.int(loops), C.int(ticks)))
+}
+
+// Like PlayChannel, but fades the sample in over ms milliseconds.
+func FadeInChannel(channel int, chunk *Chunk, loops, ms int) int {
+	return FadeInChannelTimed(channel, chunk, loops, ms, -1)
+}
+
+// Like PlayChannelTimed, but fades the sample in over ms milliseconds.
+func FadeInChannelTimed(channel int, chunk *Chunk, loops, ms, ticks int) int {
+	chunk.mutex.Lock()
+	defer chunk.mutex.Unlock()
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadeInChannelTimed(C.int(channel), chunk.cChunk, C.int(loops), C.int(ms), C.int(ticks)))
+}
+
+// Sets the volume of a channel, or of all channels if channel is -1,
+// from 0 to MAX_VOLUME. If volume is -1, only returns the volume.
+// Returns the previous volume (the average volume for all channels).
+func Volume(channel, volume int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_Volume(C.int(channel), C.int(volume)))
+}
+
+// Sets the volume of the left and right speaker of a channel, from 0 to
+// 255. Use 255, 255 to remove the effect. Returns 0 on error.
+func SetPanning(channel int, left, right uint8) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_SetPanning(C.int(channel), C.Uint8(left), C.Uint8(right)))
+}
+
+// Simulates the distance of the sound source of a channel, from 0 (near)
+// to 255 (far). Returns 0 on error.
+func SetDistance(channel int, distance uint8) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_SetDistance(C.int(channel), C.Uint8(distance)))
+}
+
+// Simulates the position of the sound source of a channel. The angle is
+// in degrees clockwise, where 0 is in front of the listener.
+// Returns 0 on error.
+func SetPosition(channel int, angle int16, distance uint8) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_SetPosition(C.int(channel), C.Sint16(angle), C.Uint8(distance)))
+}
+
+// Swaps the left and right speaker of a channel. Returns 0 on error.
+func SetReverseStereo(channel int, flip bool) int {
+	cflip := C.int(0)
+	if flip {
+		cflip = 1
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_SetReverseStereo(C.int(channel), cflip))
+}
+
+// Halts a channel, or all channels if channel is -1.
+func HaltChannel(channel int) {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_HaltChannel(C.int(channel))
+}
+
+// Halts a channel, or all channels if channel is -1, after ticks
+// milliseconds. Returns the number of channels set to expire.
+func ExpireChannel(channel, ticks int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_ExpireChannel(C.int(channel), C.int(ticks)))
+}
+
+// Fades a channel, or all channels if channel is -1, out over ms
+// milliseconds. Returns the number of channels set to fade out.
+func FadeOutChannel(channel, ms int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadeOutChannel(C.int(channel), C.int(ms)))
+}
+
+// Returns the fading status of a channel.
+func FadingChannel(channel int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadingChannel(C.int(channel)))
+}
+
+// Pauses a channel, or all channels if channel is -1.
+func Pause(channel int) {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_Pause(C.int(channel))
+}
+
+// Resumes a paused channel, or all channels if channel is -1.
+func Resume(channel int) {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_Resume(C.int(channel))
+}
+
+// Returns whether a channel is paused, or the number of paused channels
+// if channel is -1.
+func Paused(channel int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_Paused(C.int(channel)))
+}
+
+// Returns whether a channel is playing, or the number of playing channels
+// if channel is -1.
+func Playing(channel int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_Playing(C.int(channel)))
+}
+
+// Adds a channel to the group identified by tag, or removes it from its
+// group if tag is -1. Returns 1 if successful or 0 if there was an error.
+func GroupChannel(channel, tag int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GroupChannel(C.int(channel), C.int(tag)))
+}
+
+// Adds the channels from from to to (inclusive) to a group.
+// Returns the number of channels added.
+func GroupChannels(from, to, tag int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GroupChannels(C.int(from), C.int(to), C.int(tag)))
+}
+
+// Returns the first free channel in a group, or -1 if there is none.
+// A tag of -1 means all channels.
+func GroupAvailable(tag int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GroupAvailable(C.int(tag)))
+}
+
+// Returns the number of channels in a group.
+func GroupCount(tag int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GroupCount(C.int(tag)))
+}
+
+// Returns the channel in a group which has been playing the longest,
+// or -1 if none is playing.
+func GroupOldest(tag int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GroupOldest(C.int(tag)))
+}
+
+// Returns the channel in a group which started playing most recently,
+// or -1 if none is playing.
+func GroupNewer(tag int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GroupNewer(C.int(tag)))
+}
+
+// Fades the channels of a group out over ms milliseconds.
+// Returns the number of channels set to fade out.
+func FadeOutGroup(tag, ms int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadeOutGroup(C.int(tag), C.int(ms)))
+}
+
+// Halts the channels of a group.
+func HaltGroup(tag int) {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_HaltGroup(C.int(tag))
+}
@@ -0,0 +1,248 @@
+package mixer
+
+// #include <stdlib.h>
+// #include <SDL/SDL_mixer.h>
+import "C"
+import (
+	"sdl"
+	"sync"
+	"unsafe"
+)
+
+// Music types, as returned by Music.Type
+const (
+	MUS_NONE    = C.MUS_NONE
+	MUS_CMD     = C.MUS_CMD
+	MUS_WAV     = C.MUS_WAV
+	MUS_MOD     = C.MUS_MOD
+	MUS_MID     = C.MUS_MID
+	MUS_OGG     = C.MUS_OGG
+	MUS_MP3     = C.MUS_MP3
+	MUS_MP3_MAD = C.MUS_MP3_MAD
+	MUS_FLAC    = C.MUS_FLAC
+	MUS_MODPLUG = C.MUS_MODPLUG
+)
+
+// A music stream. Only one music stream plays at a time, alongside the
+// channels. Music is decoded while it plays, rather than loaded at once.
+type Music struct {
+	cMusic *C.Mix_Music
+	rw     *sdl.RWops // Read by the music while it plays
+	ownRW  bool       // Whether Free closes rw
+	mutex  sync.Mutex
+}
+
+// Loads music from a file. Returns nil on error.
+func LoadMUS(file string) *Music {
+	cfile := C.CString(file)
+	defer C.free(unsafe.Pointer(cfile))
+
+	sdl.GlobalMutex.Lock()
+	cMusic := C.Mix_LoadMUS(cfile)
+	sdl.GlobalMutex.Unlock()
+	if cMusic == nil {
+		return nil
+	}
+	return &Music{cMusic: cMusic}
+}
+
+// Loads music from an RWops. The music reads from the RWops while it
+// plays, so the RWops must not be closed before the music is freed.
+// Returns nil on error, or if rw is nil or closed.
+func LoadMUS_RW(rw *sdl.RWops) *Music {
+	crw := cRWops("LoadMUS_RW", rw)
+	if crw == nil {
+		return nil
+	}
+
+	sdl.GlobalMutex.Lock()
+	cMusic := C.Mix_LoadMUS_RW(crw)
+	sdl.GlobalMutex.Unlock()
+	if cMusic == nil {
+		return nil
+	}
+	return &Music{cMusic: cMusic, rw: rw}
+}
+
+// Loads music from memory, such as an asset embedded in the program.
+// Returns nil on error.
+func LoadMUSBytes(data []byte) *Music {
+	rw := sdl.RWFromMem(data)
+	if rw == nil {
+		return nil
+	}
+	music := LoadMUS_RW(rw)
+	if music == nil {
+		rw.Close()
+		return nil
+	}
+	music.ownRW = true
+	return music
+}
+
+// Frees the music, halting it if it is playing. Freeing twice has no
+// effect.
+func (m *Music) Free() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.cMusic != nil {
+		sdl.GlobalMutex.Lock()
+		C.Mix_FreeMusic(m.cMusic)
+		sdl.GlobalMutex.Unlock()
+		m.cMusic = nil
+	}
+	if m.ownRW {
+		m.rw.Close()
+	}
+	m.rw = nil
+}
+
+// Returns the type of the music, one of the MUS_* values.
+func (m *Music) Type() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.cMusic == nil {
+		return MUS_NONE
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_GetMusicType(m.cMusic))
+}
+
+// Plays the music, halting the music which is playing. The music is
+// played loops times, or forever if loops is -1.
+// Returns 0 if successful or -1 if there was an error.
+func (m *Music) Play(loops int) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.cMusic == nil {
+		sdl.SetError("mixer: the music has been freed")
+		return -1
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_PlayMusic(m.cMusic, C.int(loops)))
+}
+
+// Like Play, but fades the music in over ms milliseconds.
+func (m *Music) FadeIn(loops, ms int) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.cMusic == nil {
+		sdl.SetError("mixer: the music has been freed")
+		return -1
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadeInMusic(m.cMusic, C.int(loops), C.int(ms)))
+}
+
+// Like FadeIn, but starts playing at a position (see SetMusicPosition).
+func (m *Music) FadeInPos(loops, ms int, position float64) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.cMusic == nil {
+		sdl.SetError("mixer: the music has been freed")
+		return -1
+	}
+
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadeInMusicPos(m.cMusic, C.int(loops), C.int(ms), C.double(position)))
+}
+
+// Sets the volume of the music, from 0 to MAX_VOLUME, or only returns it
+// if volume is -1. Returns the previous volume.
+func VolumeMusic(volume int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_VolumeMusic(C.int(volume)))
+}
+
+// Halts the music.
+func HaltMusic() {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_HaltMusic()
+}
+
+// Fades the music out over ms milliseconds. Returns 1 if successful or 0
+// if there was an error.
+func FadeOutMusic(ms int) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadeOutMusic(C.int(ms)))
+}
+
+// Returns the fading status of the music.
+func FadingMusic() int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_FadingMusic())
+}
+
+// Pauses the music.
+func PauseMusic() {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_PauseMusic()
+}
+
+// Resumes the paused music.
+func ResumeMusic() {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_ResumeMusic()
+}
+
+// Restarts the music from the beginning.
+func RewindMusic() {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	C.Mix_RewindMusic()
+}
+
+// Returns whether the music is paused.
+func PausedMusic() bool {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return C.Mix_PausedMusic() != 0
+}
+
+// Returns whether music is playing. Paused music counts as playing.
+func PlayingMusic() bool {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return C.Mix_PlayingMusic() != 0
+}
+
+// Sets the position in the playing music: seconds for OGG, MP3 and FLAC
+// music, the pattern number for MOD music. Returns 0 if successful or -1
+// if there was an error.
+func SetMusicPosition(position float64) int {
+	sdl.GlobalMutex.Lock()
+	defer sdl.GlobalMutex.Unlock()
+
+	return int(C.Mix_SetMusicPosition(C.double(position)))
+}
@@ -0,0 +1,153 @@
+package sdl
+
+import (
+	"fmt"
+	"math"
+)
+
+// A video mode, as passed to SetVideoMode.
+type Mode struct {
+	W, H  int
+	BPP   int
+	Flags uint32
+}
+
+// Returns the mode as "WxHxBPP", for example "640x480x32".
+func (m Mode) String() string {
+	return fmt.Sprintf("%dx%dx%d", m.W, m.H, m.BPP)
+}
+
+// Sets up this video mode. See SetVideoMode.
+func (m Mode) Set() *Surface {
+	return SetVideoMode(m.W, m.H, m.BPP, m.Flags)
+}
+
+// Returns the modes available with the given flags, usually FULLSCREEN,
+// for the format of the video surface (or of the best video mode if it
+// has not been set up yet), from the largest to the smallest.
+//
+// anySize is true if any size is available with the flags, for example
+// for windows; modes is empty then. If the video subsystem has not been
+// initialized, the error is set and no modes are returned.
+func DisplayModes(flags uint32) (modes []Mode, anySize bool) {
+	info := videoInfo("DisplayModes")
+	if (info == nil) || (info.Vfmt == nil) {
+		return nil, false
+	}
+	bpp := int(info.Vfmt.BitsPerPixel)
+
+	rects := ListModes(info.Vfmt, flags)
+	if rects == nil {
+		return nil, true
+	}
+
+	for _, r := range rects {
+		modes = append(modes, Mode{int(r.W), int(r.H), bpp, flags})
+	}
+	return modes, false
+}
+
+// Returns the video info. Reports an error and returns nil if the video
+// subsystem has not been initialized.
+func videoInfo(function string) *VideoInfo {
+	info := GetVideoInfo()
+	if info == nil {
+		SetError(function + ": the video subsystem has not been initialized")
+	}
+	return info
+}
+
+// Preferences for choosing a video mode with ChooseMode.
+type ModePreferences struct {
+	W, H int // The preferred size; if 0, the current size of the display
+
+	// The preferred bits per pixel; if 0, that of the video surface (or of
+	// the best video mode if it has not been set up yet)
+	BPP int
+
+	Flags uint32 // The flags of the mode, usually FULLSCREEN
+
+	// The preferred ratio of width to height; if 0, that of the preferred
+	// size
+	Aspect float64
+
+	// Whether modes smaller than the preferred size are allowed. They are
+	// only chosen if there is no larger one.
+	AllowSmaller bool
+}
+
+// Chooses the available mode closest to the preferences. Modes are scored
+// by the difference of their size to the preferred one, with smaller modes
+// counting more, the difference of their aspect ratio, and whether
+// VideoModeOK supports them with the preferred bits per pixel. The BPP of
+// the returned mode is the one VideoModeOK suggests.
+//
+// Returns false if no mode is available, or if the video subsystem has not
+// been initialized.
+func ChooseMode(prefs ModePreferences) (Mode, bool) {
+	info := videoInfo("ChooseMode")
+	if info == nil {
+		return Mode{}, false
+	}
+	if (prefs.W <= 0) || (prefs.H <= 0) {
+		prefs.W, prefs.H = int(info.Current_w), int(info.Current_h)
+	}
+	if (prefs.BPP <= 0) && (info.Vfmt != nil) {
+		prefs.BPP = int(info.Vfmt.BitsPerPixel)
+	}
+	if (prefs.W <= 0) || (prefs.H <= 0) {
+		return Mode{}, false
+	}
+	if prefs.Aspect <= 0 {
+		prefs.Aspect = float64(prefs.W) / float64(prefs.H)
+	}
+
+	modes, anySize := DisplayModes(prefs.Flags)
+	if anySize {
+		modes = []Mode{{prefs.W, prefs.H, prefs.BPP, prefs.Flags}}
+	}
+
+	var best Mode
+	bestScore := math.Inf(1)
+	for _, m := range modes {
+		bpp := VideoModeOK(m.W, m.H, prefs.BPP, prefs.Flags)
+		if bpp == 0 {
+			continue
+		}
+		smaller := (m.W < prefs.W) || (m.H < prefs.H)
+		if smaller && !prefs.AllowSmaller {
+			continue
+		}
+
+		score := scoreMode(m, bpp, prefs)
+		if smaller {
+			// Behind all modes which are large enough
+			score += 1000
+		}
+		if score < bestScore {
+			best, bestScore = Mode{m.W, m.H, bpp, prefs.Flags}, score
+		}
+	}
+
+	return best, !math.IsInf(bestScore, 1)
+}
+
+// Returns how far the mode with the given bits per pixel is from the
+// preferences; 0 if it matches them.
+func scoreMode(m Mode, bpp int, prefs ModePreferences) float64 {
+	dw := math.Abs(float64(m.W-prefs.W)) / float64(prefs.W)
+	dh := math.Abs(float64(m.H-prefs.H)) / float64(prefs.H)
+	if (m.W < prefs.W) || (m.H < prefs.H) {
+		dw, dh = 2*dw, 2*dh
+	}
+
+	aspect := float64(m.W) / float64(m.H)
+	da := math.Abs(aspect-prefs.Aspect) / prefs.Aspect
+
+	var dbpp float64
+	if bpp != prefs.BPP {
+		dbpp = 0.5
+	}
+
+	return dw + dh + 2*da + dbpp
+}
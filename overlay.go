@@ -0,0 +1,172 @@
+package sdl
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL.h>
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// The overlays which have been created and not freed yet. Overlay is the C
+// struct itself, so it cannot record whether it has been freed.
+var overlays struct {
+	sync.Mutex
+	live map[*Overlay]bool
+}
+
+// Creates a YUV overlay of the given size and format (one of the *_OVERLAY
+// constants) for the display surface. Returns nil on error, or if display
+// is nil or has been freed.
+//
+// Overlays are scaled in hardware when they are displayed, so they are
+// suited for showing video frames.
+func CreateYUVOverlay(width, height int, format uint32, display *Surface) *Overlay {
+	if display == nil {
+		SetError("CreateYUVOverlay: the display surface is nil")
+		return nil
+	}
+	if display.freed("CreateYUVOverlay") {
+		return nil
+	}
+
+	var p *C.SDL_Overlay
+	thread.Run(func() {
+		p = C.SDL_CreateYUVOverlay(C.int(width), C.int(height), C.Uint32(format), display.cSurface)
+	})
+	if p == nil {
+		return nil
+	}
+
+	o := (*Overlay)(unsafe.Pointer(p))
+	overlays.Lock()
+	if overlays.live == nil {
+		overlays.live = make(map[*Overlay]bool)
+	}
+	overlays.live[o] = true
+	overlays.Unlock()
+	return o
+}
+
+// Reports an error and returns true if the overlay has been freed.
+func (o *Overlay) freed(function string) bool {
+	overlays.Lock()
+	live := overlays.live[o]
+	overlays.Unlock()
+
+	if !live {
+		SetError(function + ": the overlay has been freed")
+		return true
+	}
+	return false
+}
+
+func (o *Overlay) cOverlay() *C.SDL_Overlay {
+	return (*C.SDL_Overlay)(unsafe.Pointer(o))
+}
+
+// Locks the overlay for direct access to its planes.
+// Returns 0 if successful or -1 if there was an error.
+func (o *Overlay) Lock() int {
+	if o.freed("Overlay.Lock") {
+		return -1
+	}
+	var status int
+	thread.Run(func() {
+		GlobalMutex.Lock()
+		status = int(C.SDL_LockYUVOverlay(o.cOverlay()))
+		GlobalMutex.Unlock()
+	})
+	return status
+}
+
+// Unlocks a previously locked overlay.
+func (o *Overlay) Unlock() {
+	if o.freed("Overlay.Unlock") {
+		return
+	}
+	thread.Run(func() {
+		GlobalMutex.Lock()
+		C.SDL_UnlockYUVOverlay(o.cOverlay())
+		GlobalMutex.Unlock()
+	})
+}
+
+// Displays the overlay scaled to dstrect on the display surface it was
+// created for. Returns 0 if successful.
+func (o *Overlay) Display(dstrect *Rect) int {
+	if o.freed("Overlay.Display") {
+		return -1
+	}
+	var status int
+	thread.Run(func() {
+		GlobalMutex.Lock()
+		status = int(C.SDL_DisplayYUVOverlay(o.cOverlay(), (*C.SDL_Rect)(unsafe.Pointer(dstrect))))
+		GlobalMutex.Unlock()
+	})
+	return status
+}
+
+// Frees the overlay. Freeing twice has no effect; other methods report an
+// error, but the fields must not be read afterwards.
+func (o *Overlay) Free() {
+	overlays.Lock()
+	live := overlays.live[o]
+	delete(overlays.live, o)
+	overlays.Unlock()
+
+	if live {
+		thread.Run(func() {
+			GlobalMutex.Lock()
+			C.SDL_FreeYUVOverlay(o.cOverlay())
+			GlobalMutex.Unlock()
+		})
+	}
+}
+
+// Returns whether the format has separate Y, U and V planes.
+func (o *Overlay) planar() bool {
+	return (o.Format == YV12_OVERLAY) || (o.Format == IYUV_OVERLAY)
+}
+
+// Returns the number of rows of a plane. The U and V planes of planar
+// formats have half the height of the image.
+func (o *Overlay) planeHeight(i int) int {
+	if o.planar() && (i > 0) {
+		return int(o.H) / 2
+	}
+	return int(o.H)
+}
+
+// Returns the length of a row of plane i in bytes, including padding.
+// Returns 0 if the overlay has no plane i.
+func (o *Overlay) Pitch(i int) int {
+	if (i < 0) || (i >= int(o.Planes)) {
+		return 0
+	}
+	return int((*[3]uint16)(unsafe.Pointer(o.Pitches))[i])
+}
+
+// Returns the pixels of plane i, Pitch(i) bytes per row, or nil if the
+// overlay has no plane i. The slice may only be used while the overlay is
+// locked.
+//
+// YV12 overlays have a Y, a V and a U plane, IYUV overlays a Y, a U and a V
+// plane; the U and V planes have half the width and height of the image.
+// Packed formats (YUY2, UYVY and YVYU) have a single plane with two bytes
+// per pixel.
+func (o *Overlay) Plane(i int) []byte {
+	if (i < 0) || (i >= int(o.Planes)) {
+		return nil
+	}
+
+	pixels := (*[3]unsafe.Pointer)(unsafe.Pointer(o.Pixels))[i]
+	n := o.Pitch(i) * o.planeHeight(i)
+	if (pixels == nil) || (n <= 0) {
+		return nil
+	}
+	return (*[1 << 30]byte)(pixels)[:n:n]
+}
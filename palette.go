@@ -0,0 +1,136 @@
+package sdl
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL.h>
+import "C"
+import "unsafe"
+
+// Returns a copy of the palette of an 8-bit surface,
+// or nil if the surface has no palette.
+func (s *Surface) Palette() []Color {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.freed("Surface.Palette") {
+		return nil
+	}
+	p := s.Format.Palette
+	if p == nil {
+		return nil
+	}
+
+	colors := make([]Color, p.Ncolors)
+	for i := range colors {
+		colors[i] = p.color(i)
+	}
+	return colors
+}
+
+// Sets a portion of the colormap for the given 8-bit surface, starting at
+// palette index firstcolor. This is the same as calling SetPalette with
+// LOGPAL|PHYSPAL.
+//
+// Returns 1 if all colors were set as passed, 0 otherwise.
+func (s *Surface) SetColors(colors []Color, firstcolor int) int {
+	return s.SetPalette(LOGPAL|PHYSPAL, colors, firstcolor)
+}
+
+// Sets a portion of the palette for the given 8-bit surface, starting at
+// palette index firstcolor. Flags is a combination of LOGPAL, which changes
+// the logical palette used for mapping and blitting, and PHYSPAL, which
+// changes the colors shown on a HWPALETTE display without redrawing.
+//
+// Returns 1 if all colors were set as passed, 0 otherwise.
+func (s *Surface) SetPalette(flags int, colors []Color, firstcolor int) int {
+	if len(colors) == 0 {
+		return 1
+	}
+
+	s.mutex.Lock()
+	status := 0
+	if !s.freed("Surface.SetPalette") {
+		status = int(C.SDL_SetPalette(s.cSurface, C.int(flags), (*C.SDL_Color)(unsafe.Pointer(&colors[0])),
+			C.int(firstcolor), C.int(len(colors))))
+	}
+	s.mutex.Unlock()
+
+	return status
+}
+
+// A range of palette entries which is rotated by a PaletteAnimator.
+type ColorCycle struct {
+	First, Last int    // The palette indexes of the range, inclusive
+	Delay       uint32 // Milliseconds between each step of the rotation
+	Reverse     bool   // Rotate towards lower indexes
+}
+
+// Animates the palette of an 8-bit surface by rotating ranges of colors,
+// the classic color cycling technique for waterfalls, fire and the like.
+//
+// On a HWPALETTE display surface, use the flag PHYSPAL to change only the
+// displayed colors, without any need to redraw or Flip.
+type PaletteAnimator struct {
+	surface *Surface
+	flags   int
+	base    []Color
+	current []Color
+	cycles  []ColorCycle
+}
+
+// Creates an animator for the palette of the surface. The current palette
+// of the surface is used as the starting point of every cycle.
+func NewPaletteAnimator(s *Surface, flags int, cycles ...ColorCycle) *PaletteAnimator {
+	base := s.Palette()
+	return &PaletteAnimator{
+		surface: s,
+		flags:   flags,
+		base:    base,
+		current: make([]Color, len(base)),
+		cycles:  cycles,
+	}
+}
+
+// Updates the palette for the given time in milliseconds, usually the value
+// of GetTicks. Only the ranges whose position changed are sent to SDL.
+func (a *PaletteAnimator) Update(ticks uint32) {
+	for _, c := range a.cycles {
+		first, last := c.First, c.Last
+		if first < 0 {
+			first = 0
+		}
+		if last >= len(a.base) {
+			last = len(a.base) - 1
+		}
+		n := last - first + 1
+		if (n <= 1) || (c.Delay == 0) {
+			continue
+		}
+
+		shift := int((ticks / c.Delay) % uint32(n))
+		if !c.Reverse {
+			shift = (n - shift) % n
+		}
+
+		changed := false
+		for i := 0; i < n; i++ {
+			color := a.base[first+(i+shift)%n]
+			if a.current[first+i] != color {
+				a.current[first+i] = color
+				changed = true
+			}
+		}
+
+		if changed {
+			a.surface.SetPalette(a.flags, a.current[first:last+1], first)
+		}
+	}
+}
+
+// Restores the palette the animator was created with.
+func (a *PaletteAnimator) Reset() {
+	copy(a.current, a.base)
+	a.surface.SetPalette(a.flags, a.current, 0)
+}
@@ -0,0 +1,101 @@
+package sdl
+
+import "unsafe"
+
+// Locks the surface, calls f with the pixel memory of the surface and
+// unlocks the surface again, even if f panics.
+//
+// The slice covers exactly the pixels of the surface: the pixel x, y
+// starts at pix[y*pitch+x*BytesPerPixel]. It must not be used after f
+// returns. Returns 0 if successful or -1 if the surface could not be locked
+// or has been freed.
+func (s *Surface) LockPixels(f func(pix []byte, pitch int)) int {
+	if s.freed("Surface.LockPixels") {
+		return -1
+	}
+	if s.Lock() != 0 {
+		return -1
+	}
+	defer s.Unlock()
+
+	f(s.pixelBytes())
+	return 0
+}
+
+// Returns the pixel memory of the surface as a byte slice, and the pitch.
+// Returns nil if the surface has been freed or has no pixels.
+func (s *Surface) pixelBytes() ([]byte, int) {
+	pitch := int(s.Pitch)
+	if (s.Format == nil) || (s.Pixels == nil) || (s.W <= 0) || (s.H <= 0) {
+		return nil, pitch
+	}
+
+	n := (int(s.H)-1)*pitch + int(s.W)*int(s.Format.BytesPerPixel)
+	return (*[1 << 30]byte)(s.Pixels)[:n:n], pitch
+}
+
+// Returns the address of the first pixel of row y. Panics if the surface
+// does not have the given number of bytes per pixel or if y is out of range.
+// The surface must not have been freed.
+func (s *Surface) row(y int, bytesPerPixel uint8) unsafe.Pointer {
+	if s.Format.BytesPerPixel != bytesPerPixel {
+		panic("sdl: surface does not have the requested number of bytes per pixel")
+	}
+	if (y < 0) || (y >= int(s.H)) {
+		panic("sdl: row index out of range")
+	}
+	return unsafe.Pointer(uintptr(s.Pixels) + uintptr(y*int(s.Pitch)))
+}
+
+// Returns the pixels of row y of an 8-bit surface, or nil if the surface
+// has been freed. The surface must be locked while the slice is used.
+func (s *Surface) Uint8Row(y int) []uint8 {
+	if s.freed("Surface.Uint8Row") {
+		return nil
+	}
+	w := int(s.W)
+	return (*[1 << 30]uint8)(s.row(y, 1))[:w:w]
+}
+
+// Returns the pixels of row y of a 16-bit surface, or nil if the surface
+// has been freed. The surface must be locked while the slice is used.
+func (s *Surface) Uint16Row(y int) []uint16 {
+	if s.freed("Surface.Uint16Row") {
+		return nil
+	}
+	w := int(s.W)
+	return (*[1 << 29]uint16)(s.row(y, 2))[:w:w]
+}
+
+// Returns the pixels of row y of a 32-bit surface, or nil if the surface
+// has been freed. The surface must be locked while the slice is used.
+func (s *Surface) Uint32Row(y int) []uint32 {
+	if s.freed("Surface.Uint32Row") {
+		return nil
+	}
+	w := int(s.W)
+	return (*[1 << 28]uint32)(s.row(y, 4))[:w:w]
+}
+
+// Returns the raw value of the pixel at x, y in the format of the surface,
+// for surfaces with 1, 2, 3 or 4 bytes per pixel. The value can be decoded
+// with GetRGBA. Coordinates outside of the surface return 0.
+//
+// The surface must be locked if it requires locking.
+func (s *Surface) GetPixel(x, y int) uint32 {
+	if (x < 0) || (y < 0) || (x >= int(s.W)) || (y >= int(s.H)) {
+		return 0
+	}
+	return s.getPixel(x, y)
+}
+
+// Sets the raw value of the pixel at x, y, as returned by MapRGBA for the
+// format of the surface. Coordinates outside of the surface are ignored.
+//
+// The surface must be locked if it requires locking.
+func (s *Surface) SetPixel(x, y int, pixel uint32) {
+	if (x < 0) || (y < 0) || (x >= int(s.W)) || (y >= int(s.H)) {
+		return
+	}
+	s.setPixel(x, y, pixel)
+}
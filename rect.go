@@ -0,0 +1,157 @@
+package sdl
+
+import "image"
+
+// The ranges of the fields of a Rect
+const (
+	minRectCoord = -1 << 15
+	maxRectCoord = 1<<15 - 1
+	maxRectSize  = 1<<16 - 1
+)
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// Returns the rectangle with the corners x0, y0 (inclusive) and x1, y1
+// (exclusive), clamped to the ranges of the fields of a Rect. Returns
+// false in ok if it had to be clamped. x1 and y1 must not be less than x0
+// and y0.
+func rectFromBounds(x0, y0, x1, y1 int) (r Rect, ok bool) {
+	cx0 := clamp(x0, minRectCoord, maxRectCoord)
+	cy0 := clamp(y0, minRectCoord, maxRectCoord)
+	cx1 := clamp(x1, cx0, cx0+maxRectSize)
+	cy1 := clamp(y1, cy0, cy0+maxRectSize)
+
+	r = Rect{int16(cx0), int16(cy0), uint16(cx1 - cx0), uint16(cy1 - cy0)}
+	ok = (cx0 == x0) && (cy0 == y0) && (cx1 == x1) && (cy1 == y1)
+	return r, ok
+}
+
+// Returns the corners of the rectangle: x0, y0 is inside the rectangle,
+// x1, y1 is just outside of it.
+func (r Rect) bounds() (x0, y0, x1, y1 int) {
+	x0, y0 = int(r.X), int(r.Y)
+	return x0, y0, x0 + int(r.W), y0 + int(r.H)
+}
+
+// Returns whether the rectangle contains no pixels.
+func (r Rect) Empty() bool {
+	return (r.W == 0) || (r.H == 0)
+}
+
+// Returns whether the pixel x, y is inside the rectangle.
+func (r Rect) Contains(x, y int) bool {
+	x0, y0, x1, y1 := r.bounds()
+	return (x >= x0) && (x < x1) && (y >= y0) && (y < y1)
+}
+
+// Returns whether the rectangles have at least one pixel in common.
+func (r Rect) Overlaps(s Rect) bool {
+	return !r.Intersect(s).Empty()
+}
+
+// Returns the largest rectangle contained in both rectangles. If they do
+// not overlap, the result is empty.
+func (r Rect) Intersect(s Rect) Rect {
+	rx0, ry0, rx1, ry1 := r.bounds()
+	sx0, sy0, sx1, sy1 := s.bounds()
+
+	x0, y0 := maxInt(rx0, sx0), maxInt(ry0, sy0)
+	x1, y1 := minInt(rx1, sx1), minInt(ry1, sy1)
+	if (x1 <= x0) || (y1 <= y0) {
+		return Rect{}
+	}
+
+	i, _ := rectFromBounds(x0, y0, x1, y1)
+	return i
+}
+
+// Returns the smallest rectangle containing both rectangles. Empty
+// rectangles are ignored. If its size does not fit into a Rect, the result
+// is clamped and ok is false.
+func (r Rect) Union(s Rect) (u Rect, ok bool) {
+	if r.Empty() {
+		return s, true
+	}
+	if s.Empty() {
+		return r, true
+	}
+
+	rx0, ry0, rx1, ry1 := r.bounds()
+	sx0, sy0, sx1, sy1 := s.bounds()
+
+	return rectFromBounds(minInt(rx0, sx0), minInt(ry0, sy0), maxInt(rx1, sx1), maxInt(ry1, sy1))
+}
+
+// Returns the rectangle shrunk by n pixels on each side, or grown if n is
+// negative. If the rectangle is too small to shrink, the result is an
+// empty rectangle at its center. If the result does not fit into the
+// ranges of the fields of a Rect, it is clamped and ok is false.
+func (r Rect) Inset(n int) (i Rect, ok bool) {
+	x0, y0, x1, y1 := r.bounds()
+
+	if x1-x0 < 2*n {
+		x0 = (x0 + x1) / 2
+		x1 = x0
+	} else {
+		x0, x1 = x0+n, x1-n
+	}
+	if y1-y0 < 2*n {
+		y0 = (y0 + y1) / 2
+		y1 = y0
+	} else {
+		y0, y1 = y0+n, y1-n
+	}
+
+	return rectFromBounds(x0, y0, x1, y1)
+}
+
+// Returns the rectangle moved by dx, dy. If the position does not fit into
+// the range of int16, it is clamped and ok is false.
+func (r Rect) Translate(dx, dy int) (t Rect, ok bool) {
+	x0, y0, x1, y1 := r.bounds()
+	return rectFromBounds(x0+dx, y0+dy, x1+dx, y1+dy)
+}
+
+// Returns the part of the rectangle inside the clipping rectangle of the
+// surface, which is where drawing operations have an effect.
+func (r Rect) Clip(s *Surface) Rect {
+	var clip Rect
+	s.GetClipRect(&clip)
+	return r.Intersect(clip)
+}
+
+// Converts the rectangle to an image.Rectangle.
+func (r Rect) Rectangle() image.Rectangle {
+	x0, y0, x1, y1 := r.bounds()
+	return image.Rect(x0, y0, x1, y1)
+}
+
+// Converts an image.Rectangle to a Rect. If the rectangle does not fit
+// into the int16 position and uint16 size of a Rect, the result is clamped
+// and ok is false.
+func RectFromRectangle(rect image.Rectangle) (r Rect, ok bool) {
+	rect = rect.Canon()
+	return rectFromBounds(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Max.Y)
+}
@@ -0,0 +1,58 @@
+package rotozoom
+
+import "sdl"
+
+// Rotates the surface counterclockwise by turns * 90 degrees. Negative
+// values rotate clockwise. The pixels are copied exactly, without filtering.
+// Returns nil on error.
+func Rotate90(src *sdl.Surface, turns int) *sdl.Surface {
+	turns %= 4
+	if turns < 0 {
+		turns += 4
+	}
+
+	w, h := int(src.W), int(src.H)
+	switch turns {
+	case 1:
+		return remap(src, h, w, func(x, y int) (int, int) { return w - 1 - y, x })
+	case 2:
+		return remap(src, w, h, func(x, y int) (int, int) { return w - 1 - x, h - 1 - y })
+	case 3:
+		return remap(src, h, w, func(x, y int) (int, int) { return y, h - 1 - x })
+	}
+	return remap(src, w, h, func(x, y int) (int, int) { return x, y })
+}
+
+// Mirrors the surface horizontally, swapping left and right.
+// Returns nil on error.
+func FlipHorizontal(src *sdl.Surface) *sdl.Surface {
+	w, h := int(src.W), int(src.H)
+	return remap(src, w, h, func(x, y int) (int, int) { return w - 1 - x, y })
+}
+
+// Mirrors the surface vertically, swapping top and bottom.
+// Returns nil on error.
+func FlipVertical(src *sdl.Surface) *sdl.Surface {
+	w, h := int(src.W), int(src.H)
+	return remap(src, w, h, func(x, y int) (int, int) { return x, h - 1 - y })
+}
+
+// Creates a surface of size w, h like src, where each pixel x, y is copied
+// from the source pixel returned by the function from.
+func remap(src *sdl.Surface, w, h int, from func(x, y int) (int, int)) *sdl.Surface {
+	return withSupportedFormat(src, func(src *sdl.Surface) *sdl.Surface {
+		r := readRaster(src)
+		if r == nil {
+			return nil
+		}
+		dst := r.like(w, h)
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				sx, sy := from(x, y)
+				pixel, _ := r.at(sx, sy, true)
+				dst.set(x, y, pixel)
+			}
+		}
+		return dst.surface(src)
+	})
+}
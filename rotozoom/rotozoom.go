@@ -0,0 +1,189 @@
+/*
+Package rotozoom scales, rotates and flips SDL surfaces in software,
+similar to the rotozoomer of SDL_gfx.
+
+The functions work on 8-bit and 32-bit surfaces; surfaces of other depths
+are converted to 32 bits and back. The results are new surfaces in the
+format of the source, with its palette, colorkey and alpha settings.
+8-bit surfaces are always sampled with the Nearest filter, as palette
+indexes cannot be interpolated.
+*/
+package rotozoom
+
+import (
+	"math"
+	"sdl"
+)
+
+// Resampling filter
+type Filter int
+
+const (
+	// Picks the closest source pixel. Fast, and keeps hard pixel edges.
+	Nearest Filter = iota
+
+	// Interpolates between the four closest source pixels.
+	Bilinear
+
+	// Averages all source pixels covered by a destination pixel when
+	// shrinking, and interpolates like Bilinear when enlarging or rotating.
+	Smooth
+)
+
+// Scales the surface by the factors zoomx and zoomy, which must be positive.
+// Returns nil on error.
+func Zoom(src *sdl.Surface, zoomx, zoomy float64, filter Filter) *sdl.Surface {
+	if (zoomx <= 0) || (zoomy <= 0) {
+		sdl.SetError("rotozoom: zoom factors must be positive")
+		return nil
+	}
+
+	w := int(math.Max(1, math.Floor(float64(src.W)*zoomx+0.5)))
+	h := int(math.Max(1, math.Floor(float64(src.H)*zoomy+0.5)))
+	return Scale(src, w, h, filter)
+}
+
+// Scales the surface to exactly w by h pixels.
+// Returns nil on error.
+func Scale(src *sdl.Surface, w, h int, filter Filter) *sdl.Surface {
+	if (w <= 0) || (h <= 0) {
+		sdl.SetError("rotozoom: invalid size")
+		return nil
+	}
+
+	return withSupportedFormat(src, func(src *sdl.Surface) *sdl.Surface {
+		r := readRaster(src)
+		if r == nil {
+			return nil
+		}
+		var dst *raster
+		if (filter == Smooth) && (r.bpp == 4) && (w <= r.w) && (h <= r.h) {
+			dst = r.box(w, h)
+		} else {
+			sx := float64(r.w) / float64(w)
+			sy := float64(r.h) / float64(h)
+			dst = r.transform(w, h, filter, true, func(x, y float64) (float64, float64) {
+				return x * sx, y * sy
+			})
+		}
+		return dst.surface(src)
+	})
+}
+
+// Rotates the surface counterclockwise by angle degrees. The result is large
+// enough to hold the whole rotated surface; the uncovered corners are
+// transparent, or filled with the colorkey if the surface has one.
+// Returns nil on error.
+func Rotate(src *sdl.Surface, angle float64, filter Filter) *sdl.Surface {
+	return RotateZoom(src, angle, 1, filter)
+}
+
+// Rotates the surface counterclockwise by angle degrees and scales it by
+// zoom, which must be positive. See func Rotate.
+// Returns nil on error.
+func RotateZoom(src *sdl.Surface, angle, zoom float64, filter Filter) *sdl.Surface {
+	if zoom <= 0 {
+		sdl.SetError("rotozoom: zoom factor must be positive")
+		return nil
+	}
+
+	// Exact multiples of 90 degrees need no resampling
+	if (zoom == 1) && (math.Mod(angle, 90) == 0) {
+		return Rotate90(src, int(angle/90))
+	}
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	sw, sh := float64(src.W), float64(src.H)
+	w := int(math.Max(1, math.Ceil((math.Abs(sw*cos)+math.Abs(sh*sin))*zoom-1e-9)))
+	h := int(math.Max(1, math.Ceil((math.Abs(sw*sin)+math.Abs(sh*cos))*zoom-1e-9)))
+
+	return withSupportedFormat(src, func(src *sdl.Surface) *sdl.Surface {
+		r := readRaster(src)
+		if r == nil {
+			return nil
+		}
+		cx, cy := float64(w)/2, float64(h)/2
+		scx, scy := float64(r.w)/2, float64(r.h)/2
+
+		// Maps a point of the destination back into the source. The y axis
+		// points down, so a counterclockwise rotation on screen is clockwise
+		// in these coordinates.
+		dst := r.transform(w, h, filter, false, func(x, y float64) (float64, float64) {
+			u, v := (x-cx)/zoom, (y-cy)/zoom
+			return scx + u*cos - v*sin, scy + u*sin + v*cos
+		})
+		return dst.surface(src)
+	})
+}
+
+// Creates a raster of size w, h whose pixel centers are mapped into r by
+// the function inverse, and sampled with the filter. Samples outside of r
+// are clamped to the edge if clamp is true, or else transparent.
+func (r *raster) transform(w, h int, filter Filter, clamp bool, inverse func(x, y float64) (float64, float64)) *raster {
+	dst := r.like(w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sx, sy := inverse(float64(x)+0.5, float64(y)+0.5)
+			if (filter == Nearest) || (r.bpp == 1) {
+				pixel, _ := r.at(int(math.Floor(sx)), int(math.Floor(sy)), clamp)
+				dst.set(x, y, pixel)
+			} else {
+				dst.set(x, y, r.bilinear(sx, sy, clamp))
+			}
+		}
+	}
+	return dst
+}
+
+// Samples the 32-bit raster at sx, sy with bilinear interpolation.
+func (r *raster) bilinear(sx, sy float64, clamp bool) uint32 {
+	sx -= 0.5
+	sy -= 0.5
+	x0, y0 := math.Floor(sx), math.Floor(sy)
+	tx, ty := sx-x0, sy-y0
+	ix, iy := int(x0), int(y0)
+
+	var acc accumulator
+	for _, s := range [4]struct {
+		dx, dy int
+		weight float64
+	}{
+		{0, 0, (1 - tx) * (1 - ty)},
+		{1, 0, tx * (1 - ty)},
+		{0, 1, (1 - tx) * ty},
+		{1, 1, tx * ty},
+	} {
+		pixel, inside := r.at(ix+s.dx, iy+s.dy, clamp)
+		acc.add(r, pixel, inside, s.weight)
+	}
+
+	return acc.pixel(r)
+}
+
+// Shrinks the 32-bit raster to w, h by averaging the block of source
+// pixels covered by each destination pixel.
+func (r *raster) box(w, h int) *raster {
+	dst := r.like(w, h)
+	for y := 0; y < h; y++ {
+		y0, y1 := y*r.h/h, (y+1)*r.h/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0, x1 := x*r.w/w, (x+1)*r.w/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var acc accumulator
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel, inside := r.at(sx, sy, true)
+					acc.add(r, pixel, inside, 1)
+				}
+			}
+			dst.set(x, y, acc.pixel(r))
+		}
+	}
+	return dst
+}
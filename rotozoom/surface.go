@@ -0,0 +1,237 @@
+package rotozoom
+
+import "sdl"
+
+// Creates an empty surface of size w, h with the same format, palette,
+// colorkey and alpha settings as src.
+func newSurfaceLike(src *sdl.Surface, w, h int) *sdl.Surface {
+	f := src.Format
+	dst := sdl.CreateRGBSurface(sdl.SWSURFACE, w, h, int(f.BitsPerPixel), f.Rmask, f.Gmask, f.Bmask, f.Amask)
+	if dst == nil {
+		return nil
+	}
+
+	if palette := src.Palette(); palette != nil {
+		dst.SetColors(palette, 0)
+	}
+	if src.Flags&sdl.SRCCOLORKEY != 0 {
+		dst.SetColorKey(sdl.SRCCOLORKEY, f.Colorkey)
+	}
+	dst.SetAlpha(src.Flags&sdl.SRCALPHA, f.Alpha)
+
+	return dst
+}
+
+// Calls f with src, or with a 32-bit copy of src if src is neither an 8-bit
+// nor a 32-bit surface. In the latter case, the result is converted back to
+// the format of src.
+func withSupportedFormat(src *sdl.Surface, f func(src *sdl.Surface) *sdl.Surface) *sdl.Surface {
+	bpp := src.Format.BytesPerPixel
+	if (bpp == 1) || (bpp == 4) {
+		return f(src)
+	}
+
+	flags := src.Flags & (sdl.SRCCOLORKEY | sdl.SRCALPHA)
+
+	tmp := src.ConvertSurface(sdl.FormatARGB8888(), sdl.SWSURFACE|flags)
+	if tmp == nil {
+		return nil
+	}
+	result := f(tmp)
+	tmp.Free()
+	if result == nil {
+		return nil
+	}
+
+	dst := result.ConvertSurface(src.Format, sdl.SWSURFACE|flags)
+	result.Free()
+	return dst
+}
+
+// The pixels of an 8-bit or 32-bit surface, copied into Go memory so that
+// the transformations need neither locking nor pointer arithmetic.
+type raster struct {
+	w, h  int
+	bpp   uint8
+	pix8  []uint8
+	pix32 []uint32
+
+	// Settings of the surface needed for alpha-aware filtering
+	alpha  bool   // The format has an alpha channel
+	ashift uint   // Position of the alpha channel
+	keyed  bool   // The surface has a colorkey
+	key    uint32 // The colorkey
+}
+
+// Copies the pixels of an 8-bit or 32-bit surface.
+// Returns nil on error.
+func readRaster(s *sdl.Surface) *raster {
+	f := s.Format
+	r := &raster{
+		w:      int(s.W),
+		h:      int(s.H),
+		bpp:    f.BytesPerPixel,
+		alpha:  f.Amask != 0,
+		ashift: uint(f.Ashift),
+		keyed:  s.Flags&sdl.SRCCOLORKEY != 0,
+		key:    f.Colorkey,
+	}
+
+	if r.bpp == 1 {
+		r.pix8 = make([]uint8, r.w*r.h)
+	} else {
+		r.pix32 = make([]uint32, r.w*r.h)
+	}
+
+	locked := s.LockPixels(func(pix []byte, pitch int) {
+		for y := 0; y < r.h; y++ {
+			if r.bpp == 1 {
+				copy(r.pix8[y*r.w:], s.Uint8Row(y))
+			} else {
+				copy(r.pix32[y*r.w:], s.Uint32Row(y))
+			}
+		}
+	})
+	if locked != 0 {
+		return nil
+	}
+
+	return r
+}
+
+// Creates an empty raster of size w, h with the settings of r.
+func (r *raster) like(w, h int) *raster {
+	n := *r
+	n.w, n.h = w, h
+	n.pix8, n.pix32 = nil, nil
+	if r.bpp == 1 {
+		n.pix8 = make([]uint8, w*h)
+	} else {
+		n.pix32 = make([]uint32, w*h)
+	}
+	return &n
+}
+
+// Returns a new surface like src which holds the pixels of r, or nil on
+// error.
+func (r *raster) surface(src *sdl.Surface) *sdl.Surface {
+	dst := newSurfaceLike(src, r.w, r.h)
+	if dst == nil {
+		return nil
+	}
+
+	locked := dst.LockPixels(func(pix []byte, pitch int) {
+		for y := 0; y < r.h; y++ {
+			if r.bpp == 1 {
+				copy(dst.Uint8Row(y), r.pix8[y*r.w:(y+1)*r.w])
+			} else {
+				copy(dst.Uint32Row(y), r.pix32[y*r.w:(y+1)*r.w])
+			}
+		}
+	})
+	if locked != 0 {
+		dst.Free()
+		return nil
+	}
+
+	return dst
+}
+
+// The value of pixels outside of the surface: the colorkey if there is one,
+// or else a fully transparent (or black) pixel.
+func (r *raster) border() uint32 {
+	if r.keyed {
+		return r.key
+	}
+	return 0
+}
+
+// Returns the raw pixel at x, y. Coordinates outside of the raster are
+// clamped to the edge if clamp is true, or else return the border pixel.
+func (r *raster) at(x, y int, clamp bool) (pixel uint32, inside bool) {
+	if (x < 0) || (y < 0) || (x >= r.w) || (y >= r.h) {
+		if !clamp {
+			return r.border(), false
+		}
+		x = clampInt(x, 0, r.w-1)
+		y = clampInt(y, 0, r.h-1)
+	}
+
+	if r.bpp == 1 {
+		return uint32(r.pix8[y*r.w+x]), true
+	}
+	return r.pix32[y*r.w+x], true
+}
+
+// Returns the opacity (0 - 255) of a 32-bit pixel. Pixels outside of the
+// raster and pixels matching the colorkey are fully transparent.
+func (r *raster) opacity(pixel uint32, inside bool) uint32 {
+	switch {
+	case !inside:
+		return 0
+	case r.keyed && (pixel == r.key):
+		return 0
+	case r.alpha:
+		return (pixel >> r.ashift) & 0xff
+	}
+	return 0xff
+}
+
+// Sets the pixel at x, y.
+func (r *raster) set(x, y int, pixel uint32) {
+	if r.bpp == 1 {
+		r.pix8[y*r.w+x] = uint8(pixel)
+	} else {
+		r.pix32[y*r.w+x] = pixel
+	}
+}
+
+// Accumulates 32-bit pixels weighted by their opacity, so that transparent
+// pixels do not bleed their color into the result.
+type accumulator struct {
+	channels [4]float64 // Sums of the byte channels, weighted by weight*opacity
+	opacity  float64    // Sum of weight*opacity
+	weight   float64    // Sum of weights
+}
+
+func (a *accumulator) add(r *raster, pixel uint32, inside bool, weight float64) {
+	op := float64(r.opacity(pixel, inside)) * weight
+	for i := range a.channels {
+		a.channels[i] += float64((pixel>>(8*uint(i)))&0xff) * op
+	}
+	a.opacity += op
+	a.weight += weight
+}
+
+// Returns the accumulated pixel. For formats without an alpha channel,
+// the result is the border pixel if it is mostly transparent.
+func (a *accumulator) pixel(r *raster) uint32 {
+	if a.weight == 0 {
+		return r.border()
+	}
+
+	opacity := a.opacity / a.weight
+	if opacity == 0 || (!r.alpha && opacity < 128) {
+		return r.border()
+	}
+
+	var pixel uint32
+	for i, sum := range a.channels {
+		pixel |= uint32(sum/a.opacity+0.5) << (8 * uint(i))
+	}
+	if r.alpha {
+		pixel &^= 0xff << r.ashift
+		pixel |= uint32(opacity+0.5) << r.ashift
+	}
+	return pixel
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,77 @@
+package sdl
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL.h>
+//
+// static int closeRW(SDL_RWops *rw) { return SDL_RWclose(rw); }
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// A source or destination of data for SDL functions, such as a file or
+// a block of memory. Packages such as "sdl/image" load data through it.
+type RWops struct {
+	cRWops *C.SDL_RWops
+	cData  unsafe.Pointer // Memory owned by RWFromMem, freed by Close
+	mutex  sync.Mutex
+}
+
+// Opens a file for reading or writing. The mode is the same as for
+// fopen, for example "rb". Returns nil on error.
+func RWFromFile(file, mode string) *RWops {
+	cfile := C.CString(file)
+	cmode := C.CString(mode)
+	rw := C.SDL_RWFromFile(cfile, cmode)
+	C.free(unsafe.Pointer(cfile))
+	C.free(unsafe.Pointer(cmode))
+
+	if rw == nil {
+		return nil
+	}
+	return &RWops{cRWops: rw}
+}
+
+// Creates a read-only RWops reading from a copy of data.
+// Returns nil on error.
+func RWFromMem(data []byte) *RWops {
+	cData := C.CBytes(data)
+	rw := C.SDL_RWFromConstMem(cData, C.int(len(data)))
+	if rw == nil {
+		C.free(cData)
+		return nil
+	}
+	return &RWops{cRWops: rw, cData: cData}
+}
+
+// Closes the RWops and frees its memory. Closing twice has no effect.
+func (rw *RWops) Close() int {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	status := 0
+	if rw.cRWops != nil {
+		status = int(C.closeRW(rw.cRWops))
+		rw.cRWops = nil
+	}
+	if rw.cData != nil {
+		C.free(rw.cData)
+		rw.cData = nil
+	}
+	return status
+}
+
+// Returns the underlying C SDL_RWops pointer, or nil if the RWops is closed.
+//
+// There is no need to use this in application code, the function is public
+// just because it needs to be accessible from other parts of Go-SDL (such as package "sdl/image").
+func (rw *RWops) CRWops() unsafe.Pointer {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	return unsafe.Pointer(rw.cRWops)
+}
@@ -0,0 +1,112 @@
+package sdl
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Returns a copy of the current display surface as an image, or nil on
+// error. The surface is locked while it is read.
+//
+// The pixels of a single-buffered display surface, hardware or software,
+// are the frame shown by the last Flip or UpdateRect plus what has been
+// drawn since. Call Screenshot right after Flip to capture the frame on
+// display, or before Flip to capture the frame about to be shown.
+//
+// The pixels of a DOUBLEBUF display surface are the back buffer, not the
+// frame on display, and SDL offers no way to read the front buffer, so
+// DOUBLEBUF and OPENGL display surfaces are reported as an error.
+func Screenshot() *image.NRGBA {
+	screen := GetVideoSurface()
+	if screen == nil {
+		SetError("Screenshot: no video mode has been set")
+		return nil
+	}
+	if screen.Flags&OPENGL != 0 {
+		SetError("Screenshot: cannot read an OpenGL display surface")
+		return nil
+	}
+	if screen.Flags&DOUBLEBUF != 0 {
+		SetError("Screenshot: cannot read the front buffer of a DOUBLEBUF display surface")
+		return nil
+	}
+
+	if screen.Lock() != 0 {
+		return nil
+	}
+	defer screen.Unlock()
+
+	w, h := int(screen.W), int(screen.H)
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := screen.pixelColor(screen.getPixel(x, y))
+			// The display has no meaningful alpha channel
+			img.SetNRGBA(x, y, color.NRGBA{c.R, c.G, c.B, 0xff})
+		}
+	}
+
+	return img
+}
+
+// Saves a screenshot of the current display surface as a PNG file.
+// Returns 0 if successful or -1 if there was an error.
+func SaveScreenshot(file string) int {
+	img := Screenshot()
+	if img == nil {
+		return -1
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		SetError(err.Error())
+		return -1
+	}
+
+	err = png.Encode(f, img)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		SetError(err.Error())
+		return -1
+	}
+
+	return 0
+}
+
+// Saves timestamped screenshots into a directory whenever a key is pressed.
+//
+//	hotkey := &sdl.ScreenshotHotkey{Key: sdl.K_F12, Dir: "screenshots"}
+//	...
+//	case event := <-sdl.Events:
+//		hotkey.HandleEvent(event)
+type ScreenshotHotkey struct {
+	Key Key    // The key which triggers a screenshot, for example K_F12
+	Dir string // The directory where screenshots are saved, created if needed
+}
+
+// Saves a screenshot if the event is a press of the hotkey. Returns the name
+// of the saved file, or "" if the event was not a press of the hotkey.
+func (h *ScreenshotHotkey) HandleEvent(event interface{}) (file string, err error) {
+	e, ok := event.(KeyboardEvent)
+	if !ok || (e.Type != KEYDOWN) || (Key(e.Keysym.Sym) != h.Key) {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(h.Dir, 0755); err != nil {
+		return "", err
+	}
+
+	file = filepath.Join(h.Dir, "screenshot-"+time.Now().Format("20060102-150405.000")+".png")
+	if SaveScreenshot(file) != 0 {
+		return "", errors.New(GetError())
+	}
+
+	return file, nil
+}
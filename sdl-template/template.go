@@ -32,24 +32,32 @@ func main() {
 
 	sdl.WM_SetCaption("Template", "")
 
-	ticker := time.NewTicker(1e9 / 2 /*2 Hz*/ )
-
-loop:
-	for {
-		select {
-		case <-ticker.C:
-			// Note: For better efficiency, use UpdateRects instead of Flip
-			screen.FillRect(nil, /*color*/ rand.Uint32())
-			//screen.Blit(&sdl.Rect{x,y, 0, 0}, image, nil)
-			screen.Flip()
-
-		case event := <-sdl.Events:
-			fmt.Printf("%#v\n", event)
-
-			switch event.(type) {
-			case sdl.QuitEvent:
-				break loop
-			}
+	loop := sdl.NewLoop(2 /*Hz*/)
+	loop.MaxFPS = 60
+
+	loop.Event = func(event interface{}) {
+		fmt.Printf("%#v\n", event)
+
+		switch event.(type) {
+		case sdl.QuitEvent:
+			loop.Stop()
 		}
 	}
+
+	var color uint32
+
+	// Change the state of the game here, drawing happens in Render
+	loop.Update = func(step time.Duration) {
+		color = rand.Uint32()
+	}
+
+	loop.Render = func(alpha float64) {
+		screen.FillRect(nil, color)
+		//screen.Blit(&sdl.Rect{x,y, 0, 0}, image, nil)
+
+		// Note: For better efficiency, use UpdateRects instead of Flip
+		screen.Flip()
+	}
+
+	loop.Run()
 }
@@ -43,7 +43,8 @@ type Surface struct {
 	Pixels unsafe.Pointer
 	Offset int32
 
-	gcPixels interface{} // Prevents garbage collection of pixels passed to func CreateRGBSurfaceFrom
+	gcPixels interface{}   // Prevents garbage collection of pixels passed to func CreateRGBSurfaceFrom
+	dirty    *DirtyTracker // Records the regions changed by Blit and FillRect, if set
 }
 
 func wrap(cSurface *C.SDL_Surface) *Surface {
@@ -53,6 +54,7 @@ func wrap(cSurface *C.SDL_Surface) *Surface {
 		var surface Surface
 		surface.setCSurface(unsafe.Pointer(cSurface))
 		s = &surface
+		track(s)
 	} else {
 		s = nil
 	}
@@ -60,6 +62,24 @@ func wrap(cSurface *C.SDL_Surface) *Surface {
 	return s
 }
 
+// Reports an error and returns true if the surface has been freed.
+func (s *Surface) freed(function string) bool {
+	if s.cSurface == nil {
+		SetError(function + ": the surface has been freed")
+		return true
+	}
+	return false
+}
+
+// Wraps a pointer to a C SDL_Surface into a new Surface.
+// Returns nil if the pointer is nil.
+//
+// There is no need to use this in application code, the function is public
+// just because it needs to be accessible from other parts of Go-SDL (such as package "sdl/ttf").
+func WrapSurface(cSurface unsafe.Pointer) *Surface {
+	return wrap((*C.SDL_Surface)(cSurface))
+}
+
 func (s *Surface) setCSurface(cSurface unsafe.Pointer) {
 	s.cSurface = (*C.SDL_Surface)(cSurface)
 	s.reload()
@@ -72,6 +92,13 @@ func (s *Surface) reload() {
 	s.Format = (*PixelFormat)(unsafe.Pointer(s.cSurface.format))
 	s.W = int32(s.cSurface.w)
 	s.H = int32(s.cSurface.h)
+	s.reloadPixels()
+}
+
+// Pull the pixel pointer from C.SDL_Surface. SDL_LockSurface and
+// SDL_UnlockSurface change it for hardware surfaces and surfaces with an
+// offset, so this must be called after them.
+func (s *Surface) reloadPixels() {
 	s.Pitch = uint16(s.cSurface.pitch)
 	s.Pixels = s.cSurface.pixels
 	s.Offset = int32(s.cSurface.offset)
@@ -80,6 +107,9 @@ func (s *Surface) reload() {
 func (s *Surface) destroy() {
 	s.cSurface = nil
 	s.Format = nil
+	s.W = 0
+	s.H = 0
+	s.Pitch = 0
 	s.Pixels = nil
 	s.gcPixels = nil
 }
@@ -137,6 +167,15 @@ func SetThreadbound(tb Threadbound) {
 	thread = tb
 }
 
+// Runs f on the Threadbound set with SetThreadbound, or immediately in the
+// calling goroutine if there is none.
+//
+// There is no need to use this in application code, the function is public
+// just because it needs to be accessible from other parts of Go-SDL (such as package "sdl/ttf").
+func RunThreadbound(f func()) {
+	thread.Run(f)
+}
+
 // Initializes SDL.
 func Init(flags uint32) int {
 	var status int
@@ -172,6 +211,7 @@ func Quit() {
 		currentVideoSurface = nil
 	}
 	C.SDL_Quit()
+	reportLeaks()
 }
 
 // Initializes subsystems.
@@ -0,0 +1,55 @@
+package sprite
+
+import "sort"
+
+// A group of sprites, drawn in the order of their Z, or in the order they
+// were added if their Z is equal.
+type Layer struct {
+	Visible bool
+
+	sprites []*Sprite
+}
+
+// Creates an empty, visible layer.
+func NewLayer() *Layer {
+	return &Layer{Visible: true}
+}
+
+// Adds a sprite to the layer. Adding a sprite which is already in the
+// layer has no effect.
+func (l *Layer) Add(s *Sprite) {
+	for _, t := range l.sprites {
+		if t == s {
+			return
+		}
+	}
+	l.sprites = append(l.sprites, s)
+}
+
+// Removes a sprite from the layer.
+func (l *Layer) Remove(s *Sprite) {
+	for i, t := range l.sprites {
+		if t == s {
+			l.sprites = append(l.sprites[:i], l.sprites[i+1:]...)
+			return
+		}
+	}
+}
+
+// Removes all sprites from the layer.
+func (l *Layer) Clear() {
+	l.sprites = nil
+}
+
+// Returns the sprites of the layer in drawing order.
+func (l *Layer) Sprites() []*Sprite {
+	sprites := make([]*Sprite, len(l.sprites))
+	copy(sprites, l.sprites)
+
+	// The layer keeps the order in which sprites were added, and a stable
+	// sort keeps that order for sprites with the same Z
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return sprites[i].Z < sprites[j].Z
+	})
+	return sprites
+}
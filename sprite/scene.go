@@ -0,0 +1,221 @@
+package sprite
+
+import "sdl"
+
+// Everything about a sprite which affects what is drawn
+type drawState struct {
+	Sprite
+	layer int
+	order int // The position in the drawing order of the layer
+}
+
+// Layers of sprites drawn over a background.
+type Scene struct {
+	// Drawn below all layers, at 0, 0 of the destination. If nil,
+	// BackgroundColor is used instead.
+	Background *sdl.Surface
+
+	// The pixel value filled in below all layers if Background is nil
+	BackgroundColor uint32
+
+	layers  []*Layer
+	drawn   map[*Sprite]drawState // What was drawn by the previous Update
+	dirty   []sdl.Rect            // Areas invalidated since the previous Update
+	invalid bool                  // Whether everything has to be redrawn
+}
+
+// Creates a scene without layers.
+func NewScene() *Scene {
+	return &Scene{invalid: true}
+}
+
+// Creates a new layer above all other layers and returns it.
+func (sc *Scene) AddLayer() *Layer {
+	l := NewLayer()
+	sc.layers = append(sc.layers, l)
+	return l
+}
+
+// Returns the layers, from the bottom to the top.
+func (sc *Scene) Layers() []*Layer {
+	layers := make([]*Layer, len(sc.layers))
+	copy(layers, sc.layers)
+	return layers
+}
+
+// Removes a layer from the scene.
+func (sc *Scene) RemoveLayer(l *Layer) {
+	for i, t := range sc.layers {
+		if t == l {
+			sc.layers = append(sc.layers[:i], sc.layers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Marks an area to be redrawn by the next Update, for changes the scene
+// cannot detect, such as new pixels in a sheet or the background.
+func (sc *Scene) Invalidate(r sdl.Rect) {
+	sc.dirty = append(sc.dirty, r)
+}
+
+// Marks everything to be redrawn by the next Update.
+func (sc *Scene) InvalidateAll() {
+	sc.invalid = true
+}
+
+// Returns what is currently visible.
+func (sc *Scene) snapshot() map[*Sprite]drawState {
+	states := make(map[*Sprite]drawState)
+	for i, l := range sc.layers {
+		if !l.Visible {
+			continue
+		}
+		for j, s := range l.Sprites() {
+			if s.Visible && (s.Sheet != nil) {
+				states[s] = drawState{*s, i, j}
+			}
+		}
+	}
+	return states
+}
+
+// Returns the visible sprites overlapping the area, in drawing order.
+func (sc *Scene) spritesIn(r sdl.Rect) []*Sprite {
+	var sprites []*Sprite
+	for _, l := range sc.layers {
+		if !l.Visible {
+			continue
+		}
+		for _, s := range l.Sprites() {
+			if s.Visible && (s.Sheet != nil) && s.Bounds().Overlaps(r) {
+				sprites = append(sprites, s)
+			}
+		}
+	}
+	return sprites
+}
+
+// Draws the background and the sprites inside the area.
+func (sc *Scene) redraw(dst *sdl.Surface, r sdl.Rect) {
+	var clip sdl.Rect
+	dst.GetClipRect(&clip)
+	dst.SetClipRect(&r)
+
+	if sc.Background != nil {
+		src, dstrect := r, r
+		dst.Blit(&dstrect, sc.Background, &src)
+	} else {
+		fill := r
+		dst.FillRect(&fill, sc.BackgroundColor)
+	}
+
+	for _, s := range sc.spritesIn(r) {
+		s.Draw(dst)
+	}
+
+	dst.SetClipRect(&clip)
+}
+
+// Merges overlapping rectangles, so that no area is drawn twice.
+func merge(rects []sdl.Rect) []sdl.Rect {
+	var merged []sdl.Rect
+	for _, r := range rects {
+		if r.Empty() {
+			continue
+		}
+		// Merge r with every rectangle it overlaps, until it overlaps none
+		for again := true; again; {
+			again = false
+			for i, m := range merged {
+				if m.Overlaps(r) {
+					// Clamping only matters far outside of any surface
+					r, _ = r.Union(m)
+					merged = append(merged[:i], merged[i+1:]...)
+					again = true
+					break
+				}
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Redraws the areas of dst which changed since the previous call and
+// returns them, so that they can be passed to dst.UpdateRects. The first
+// call draws everything.
+//
+// Changes are detected by comparing the sprites and layers with what was
+// drawn before. The scene must be the only thing drawing on dst, and each
+// Scene must only be used with one destination.
+func (sc *Scene) Update(dst *sdl.Surface) []sdl.Rect {
+	var clip sdl.Rect
+	dst.GetClipRect(&clip)
+
+	states := sc.snapshot()
+
+	var dirty []sdl.Rect
+	if sc.invalid {
+		dirty = []sdl.Rect{clip}
+	} else {
+		dirty = sc.dirty
+		for s, state := range states {
+			old, ok := sc.drawn[s]
+			if ok && (old == state) {
+				continue
+			}
+			dirty = append(dirty, state.Bounds())
+			if ok {
+				dirty = append(dirty, old.Bounds())
+			}
+		}
+		for s, old := range sc.drawn {
+			if _, ok := states[s]; !ok {
+				dirty = append(dirty, old.Bounds())
+			}
+		}
+	}
+
+	var updated []sdl.Rect
+	for _, r := range merge(dirty) {
+		r = r.Intersect(clip)
+		if !r.Empty() {
+			sc.redraw(dst, r)
+			updated = append(updated, r)
+		}
+	}
+
+	sc.drawn = states
+	sc.dirty = nil
+	sc.invalid = false
+
+	return updated
+}
+
+// Draws everything onto dst, for example for double-buffered screens which
+// need to be redrawn completely every frame.
+func (sc *Scene) Draw(dst *sdl.Surface) {
+	sc.InvalidateAll()
+	sc.Update(dst)
+}
+
+// Returns the topmost visible sprite containing the point x, y, or nil if
+// there is none. If precise is set, transparent pixels are ignored (see
+// Sprite.Hit).
+func (sc *Scene) HitTest(x, y int, precise bool) *Sprite {
+	for i := len(sc.layers) - 1; i >= 0; i-- {
+		l := sc.layers[i]
+		if !l.Visible {
+			continue
+		}
+		sprites := l.Sprites()
+		for j := len(sprites) - 1; j >= 0; j-- {
+			s := sprites[j]
+			if s.Visible && (s.Sheet != nil) && s.Hit(x, y, precise) {
+				return s
+			}
+		}
+	}
+	return nil
+}
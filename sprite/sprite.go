@@ -0,0 +1,151 @@
+/*
+Package sprite draws sprites onto SDL surfaces.
+
+A Sprite is an area of a sheet (any surface) drawn at a position. Sprites
+are grouped in Layers, which are drawn in order by a Scene. The Scene keeps
+track of what it drew in the previous frame, so that Scene.Update only has
+to redraw the areas which changed, and returns them for Surface.UpdateRects:
+
+	scene := sprite.NewScene()
+	layer := scene.AddLayer()
+	player := sprite.NewSprite(sheet, sdl.Rect{W: 16, H: 16})
+	layer.Add(player)
+	for {
+		player.X++
+		screen.UpdateRects(scene.Update(screen))
+	}
+*/
+package sprite
+
+import (
+	"image"
+	"sdl"
+)
+
+// An area of a sheet which is drawn at a position.
+type Sprite struct {
+	Sheet  *sdl.Surface
+	Source sdl.Rect // The area of Sheet; if W or H is 0, the whole sheet
+	X, Y   int      // The position of the top-left corner
+	Z      int      // Sprites with a higher Z are drawn over those in the same layer with a lower one
+
+	Visible bool
+
+	// If ColorKeyed is set, the pixels of the sheet with the value ColorKey
+	// are transparent. Otherwise the colorkey of the sheet is left as it is,
+	// for example as set by the loader of the sheet.
+	ColorKeyed bool
+	ColorKey   uint32
+
+	// The opacity of the sprite, from 0 (invisible) to 255 (opaque). Only
+	// used for sheets without an alpha channel; sheets with an alpha
+	// channel are blended with their per-pixel alpha.
+	Alpha uint8
+}
+
+// Creates a visible, opaque sprite at 0, 0 showing the area source of the
+// sheet.
+func NewSprite(sheet *sdl.Surface, source sdl.Rect) *Sprite {
+	return &Sprite{
+		Sheet:   sheet,
+		Source:  source,
+		Visible: true,
+		Alpha:   255,
+	}
+}
+
+// Returns the area of the sheet which is drawn.
+func (s *Sprite) source() sdl.Rect {
+	if (s.Source.W == 0) || (s.Source.H == 0) {
+		return sdl.Rect{W: uint16(s.Sheet.W), H: uint16(s.Sheet.H)}
+	}
+	return s.Source
+}
+
+// Returns the area covered by the sprite, which is clamped if it does not
+// fit into a Rect.
+func (s *Sprite) Bounds() sdl.Rect {
+	src := s.source()
+	r, _ := sdl.RectFromRectangle(image.Rect(s.X, s.Y, s.X+int(src.W), s.Y+int(src.H)))
+	return r
+}
+
+// Sets the colorkey and alpha of the sheet for drawing the sprite. The sheet
+// is only changed if its settings differ, because setting them is slow.
+func (s *Sprite) applyTransparency() {
+	sheet := s.Sheet
+
+	if s.ColorKeyed {
+		if (sheet.Flags&sdl.SRCCOLORKEY == 0) || (sheet.Format.Colorkey != s.ColorKey) {
+			sheet.SetColorKey(sdl.SRCCOLORKEY, s.ColorKey)
+		}
+	}
+
+	if sheet.Format.Amask != 0 {
+		return
+	}
+	if s.Alpha == 255 {
+		if sheet.Flags&sdl.SRCALPHA != 0 {
+			sheet.SetAlpha(0, 255)
+		}
+	} else if (sheet.Flags&sdl.SRCALPHA == 0) || (sheet.Format.Alpha != s.Alpha) {
+		sheet.SetAlpha(sdl.SRCALPHA, s.Alpha)
+	}
+}
+
+// Draws the sprite onto dst, whether it is visible or not.
+// Returns 0 if successful.
+func (s *Sprite) Draw(dst *sdl.Surface) int {
+	r := s.Bounds()
+	if (int(r.X) != s.X) || (int(r.Y) != s.Y) {
+		// Outside of the range of positions of any surface
+		return 0
+	}
+
+	s.applyTransparency()
+	src := s.source()
+	return dst.Blit(&sdl.Rect{X: r.X, Y: r.Y}, s.Sheet, &src)
+}
+
+// Returns whether the point x, y is inside the area covered by the sprite.
+// If precise is set, transparent pixels of the sprite are not considered
+// inside; this locks the sheet.
+func (s *Sprite) Hit(x, y int, precise bool) bool {
+	if !s.Bounds().Contains(x, y) {
+		return false
+	}
+	if !precise {
+		return true
+	}
+	if (s.Sheet.Format.Amask == 0) && (s.Alpha == 0) {
+		return false
+	}
+
+	src := s.source()
+	sx, sy := int(src.X)+x-s.X, int(src.Y)+y-s.Y
+
+	// The transparency is computed from the raw pixel rather than set on
+	// the sheet, which other sprites may share
+	sheet := s.Sheet
+	if sheet.Lock() != 0 {
+		return false
+	}
+	pixel := sheet.GetPixel(sx, sy)
+	sheet.Unlock()
+
+	f := sheet.Format
+	switch {
+	case s.ColorKeyed:
+		if pixel == s.ColorKey {
+			return false
+		}
+	case sheet.Flags&sdl.SRCCOLORKEY != 0:
+		if pixel == f.Colorkey {
+			return false
+		}
+	}
+	if f.Amask != 0 {
+		return pixel&f.Amask != 0
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+// Checks that OpenGL attributes set with GLConfig can be read back with
+// GL_GetConfig and GL_GetAttribute. It uses Mesa's software rasterizer, so
+// that it runs without a GPU, for example under Xvfb:
+//
+//	xvfb-run go run testgl/test.go
+//
+// The program exits with status 1 if a check fails.
+package main
+
+import (
+	"log"
+	"os"
+	"sdl"
+)
+
+func main() {
+	log.SetFlags(0)
+	if os.Getenv("LIBGL_ALWAYS_SOFTWARE") == "" {
+		os.Setenv("LIBGL_ALWAYS_SOFTWARE", "1")
+	}
+
+	tb := sdl.NewThreadbound()
+	sdl.SetThreadbound(tb)
+	go tb.Drain()
+	defer tb.Close()
+
+	if sdl.Init(sdl.INIT_VIDEO) != 0 {
+		log.Fatal(sdl.GetError())
+	}
+	defer sdl.Quit()
+
+	want := sdl.DefaultGLConfig()
+	want.RedSize, want.GreenSize, want.BlueSize = 8, 8, 8
+	want.DepthSize = 24
+	want.StencilSize = 8
+	want.DoubleBuffer = true
+
+	if sdl.GL_LoadLibrary("") != 0 {
+		log.Fatal("GL_LoadLibrary: ", sdl.GetError())
+	}
+	if want.SetVideoMode(64, 64, 0, 0) == nil {
+		log.Fatal("SetVideoMode: ", sdl.GetError())
+	}
+	if sdl.GL_GetProcAddress("glGetString") == nil {
+		log.Fatal("GL_GetProcAddress: glGetString not found")
+	}
+
+	got := sdl.GL_GetConfig()
+	if got == nil {
+		log.Fatal("GL_GetConfig: ", sdl.GetError())
+	}
+	log.Printf("requested %+v", *want)
+	log.Printf("got       %+v", *got)
+
+	failed := false
+	check := func(name string, ok bool) {
+		if !ok {
+			log.Println("FAIL:", name)
+			failed = true
+		}
+	}
+
+	// The driver may give more than requested, but not less
+	check("RedSize", got.RedSize >= want.RedSize)
+	check("GreenSize", got.GreenSize >= want.GreenSize)
+	check("BlueSize", got.BlueSize >= want.BlueSize)
+	check("DepthSize", got.DepthSize >= want.DepthSize)
+	check("StencilSize", got.StencilSize >= want.StencilSize)
+	check("DoubleBuffer", got.DoubleBuffer == want.DoubleBuffer)
+
+	// GL_GetConfig must agree with single GL_GetAttribute calls
+	attributes := []struct {
+		name  string
+		attr  sdl.GLAttr
+		value int
+	}{
+		{"GL_RED_SIZE", sdl.GL_RED_SIZE, got.RedSize},
+		{"GL_DEPTH_SIZE", sdl.GL_DEPTH_SIZE, got.DepthSize},
+		{"GL_STENCIL_SIZE", sdl.GL_STENCIL_SIZE, got.StencilSize},
+	}
+	for _, a := range attributes {
+		var value int
+		check(a.name, (sdl.GL_GetAttribute(a.attr, &value) == 0) && (value == a.value))
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+	log.Println("OK")
+}
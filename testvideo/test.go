@@ -84,8 +84,6 @@ func main() {
 	sdl.WM_SetIcon(image, nil)
 	println("WM_SetIcon ok")
 
-	running := true
-
 	if sdl.GetKeyName(270) != "[+]" {
 		log.Fatal("GetKeyName broken")
 	}
@@ -98,74 +96,93 @@ func main() {
 	out := make(chan Point)
 	go worm(in, out, draw)
 
-	tick := time.Tick(time.Second / 60) // 60 Hz
-
-	// Note: The following code is highly ineffective.
-	//       It is eating too much CPU. If you intend to use sdl,
-	//       you should do better than this.
-
-	for running {
-		select {
-		case <-tick:
-			screen.FillRect(nil, 0x00ffff)
-		loop: for {
-				select {
-				case p := <-draw:
-					screen.Blit(&sdl.Rect{int16(p.x), int16(p.y), 0, 0}, image, nil)
-				case <-out:
-				default:
-					break loop
-				}
-			}
-			var p Point
-			p.x, p.y, _ = sdl.GetMouseState()
-			worm_in <- p
-			screen.Flip()
-		case _event := <-sdl.Events:
-			switch e := _event.(type) {
+	// The loop sleeps between frames instead of spinning on a ticker
+	loop := sdl.NewLoop(60 /*Hz*/)
+	loop.MaxFPS = 60
+
+	// The positions of the worms, updated at a fixed rate and drawn
+	// every frame
+	var worms []Point
+
+	loop.Update = func(step time.Duration) {
+		var moved []Point
+	drain:
+		for {
+			select {
+			case p := <-draw:
+				moved = append(moved, p)
+			case <-out:
 			default:
-				println("unknown event")
-			case sdl.ActiveEvent:
-				println("window made active")
-			case sdl.QuitEvent:
-				running = false
-			case sdl.KeyboardEvent:
-				println("")
-				println(e.Keysym.Sym, ": ", sdl.GetKeyName(sdl.Key(e.Keysym.Sym)))
-
-				if e.Keysym.Sym == sdl.K_ESCAPE {
-					running = false
-				}
-				fmt.Printf("%04x ", e.Type)
-				for i := 0; i < len(e.Pad0); i++ {
-					fmt.Printf("%02x ", e.Pad0[i])
-				}
-				println()
-				fmt.Printf("Type: %02x Which: %02x State: %02x Pad: %02x\n", e.Type, e.Which, e.State, e.Pad0[0])
-				fmt.Printf("Scancode: %02x Sym: %08x Mod: %04x Unicode: %04x\n", e.Keysym.Scancode, e.Keysym.Sym, e.Keysym.Mod, e.Keysym.Unicode)
-			case sdl.MouseMotionEvent:
-			case sdl.MouseButtonEvent:
-				if e.Type == sdl.MOUSEBUTTONDOWN {
-					println("Click:", e.X, e.Y)
-					in = out
-					out = make(chan Point)
-					go worm(in, out, draw)
-				}
-			case sdl.JoyAxisEvent:
-				println("Joystick Axis Event ->", "Type", e.Type, "Axis:", e.Axis, " Value:", e.Value, "Which:", e.Which)
-			case sdl.JoyButtonEvent:
-				println("Joystick Button Event ->", e.Button)
-				println("State of button", e.Button, "->", joy.GetButton(int(e.Button)))
-			case sdl.ResizeEvent:
-				println("resize screen ", e.W, e.H)
-				screen = sdl.SetVideoMode(int(e.W), int(e.H), 32, sdl.RESIZABLE)
-				if screen == nil {
-					log.Fatal(sdl.GetError())
-				}
-				println("resize ok")
+				break drain
 			}
 		}
+		if len(moved) > 0 {
+			worms = moved
+		}
+
+		var p Point
+		p.x, p.y, _ = sdl.GetMouseState()
+		worm_in <- p
 	}
+
+	loop.Render = func(alpha float64) {
+		screen.FillRect(nil, 0x00ffff)
+		for _, p := range worms {
+			screen.Blit(&sdl.Rect{int16(p.x), int16(p.y), 0, 0}, image, nil)
+		}
+		screen.Flip()
+	}
+
+	loop.Event = func(_event interface{}) {
+		switch e := _event.(type) {
+		default:
+			println("unknown event")
+		case sdl.ActiveEvent:
+			println("window made active")
+		case sdl.QuitEvent:
+			loop.Stop()
+		case sdl.KeyboardEvent:
+			println("")
+			println(e.Keysym.Sym, ": ", sdl.GetKeyName(sdl.Key(e.Keysym.Sym)))
+
+			if e.Keysym.Sym == sdl.K_ESCAPE {
+				loop.Stop()
+			}
+			fmt.Printf("%04x ", e.Type)
+			for i := 0; i < len(e.Pad0); i++ {
+				fmt.Printf("%02x ", e.Pad0[i])
+			}
+			println()
+			fmt.Printf("Type: %02x Which: %02x State: %02x Pad: %02x\n", e.Type, e.Which, e.State, e.Pad0[0])
+			fmt.Printf("Scancode: %02x Sym: %08x Mod: %04x Unicode: %04x\n", e.Keysym.Scancode, e.Keysym.Sym, e.Keysym.Mod, e.Keysym.Unicode)
+		case sdl.MouseMotionEvent:
+		case sdl.MouseButtonEvent:
+			if e.Type == sdl.MOUSEBUTTONDOWN {
+				println("Click:", e.X, e.Y)
+				in = out
+				out = make(chan Point)
+				go worm(in, out, draw)
+			}
+		case sdl.JoyAxisEvent:
+			println("Joystick Axis Event ->", "Type", e.Type, "Axis:", e.Axis, " Value:", e.Value, "Which:", e.Which)
+		case sdl.JoyButtonEvent:
+			println("Joystick Button Event ->", e.Button)
+			println("State of button", e.Button, "->", joy.GetButton(int(e.Button)))
+		case sdl.ResizeEvent:
+			println("resize screen ", e.W, e.H)
+			screen = sdl.SetVideoMode(int(e.W), int(e.H), 32, sdl.RESIZABLE)
+			if screen == nil {
+				log.Fatal(sdl.GetError())
+			}
+			println("resize ok")
+		}
+	}
+
+	loop.Run()
+
+	stats := loop.Stats()
+	fmt.Printf("%.1f fps, %v per frame\n", stats.FPS, stats.FrameTime)
+
 	if sdl.JoystickOpened(0) > 0 {
 		joy.Close()
 	}
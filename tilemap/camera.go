@@ -0,0 +1,40 @@
+package tilemap
+
+import "sdl"
+
+// The part of a map which is shown, and where it is drawn.
+type Camera struct {
+	X, Y     int      // The position in the map, in pixels, shown at the top-left corner of the viewport
+	Viewport sdl.Rect // The area of the destination surface; if W or H is 0, the whole surface
+}
+
+// Moves the camera so that the map position x, y is in the middle of the
+// viewport, without showing anything outside of the map. Maps smaller than
+// the viewport are centered instead.
+func (c *Camera) CenterOn(x, y int, m *Map) {
+	c.X = centerOn(x, int(c.Viewport.W), m.Width*m.TileWidth)
+	c.Y = centerOn(y, int(c.Viewport.H), m.Height*m.TileHeight)
+}
+
+// Returns the start of a view of the given size centered on pos, along one
+// axis of a map of the given size.
+func centerOn(pos, view, size int) int {
+	if size <= view {
+		return (size - view) / 2
+	}
+
+	start := pos - view/2
+	if start < 0 {
+		return 0
+	}
+	if start > size-view {
+		return size - view
+	}
+	return start
+}
+
+// Converts a position on the destination surface, for example of the
+// mouse, to a position in the map in pixels.
+func (c *Camera) ToMap(x, y int) (int, int) {
+	return x - int(c.Viewport.X) + c.X, y - int(c.Viewport.Y) + c.Y
+}
@@ -0,0 +1,348 @@
+package tilemap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonMap struct {
+	Orientation     string         `json:"orientation"`
+	Width           int            `json:"width"`
+	Height          int            `json:"height"`
+	TileWidth       int            `json:"tilewidth"`
+	TileHeight      int            `json:"tileheight"`
+	Infinite        bool           `json:"infinite"`
+	BackgroundColor string         `json:"backgroundcolor"`
+	Properties      []jsonProperty `json:"properties"`
+	Tilesets        []jsonTileset  `json:"tilesets"`
+	Layers          []jsonLayer    `json:"layers"`
+}
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type jsonTileset struct {
+	FirstGID         uint32    `json:"firstgid"`
+	Source           string    `json:"source"`
+	Name             string    `json:"name"`
+	TileWidth        int       `json:"tilewidth"`
+	TileHeight       int       `json:"tileheight"`
+	Spacing          int       `json:"spacing"`
+	Margin           int       `json:"margin"`
+	TileCount        int       `json:"tilecount"`
+	Columns          int       `json:"columns"`
+	Image            string    `json:"image"`
+	ImageWidth       int       `json:"imagewidth"`
+	ImageHeight      int       `json:"imageheight"`
+	TransparentColor string    `json:"transparentcolor"`
+	Tiles            jsonTiles `json:"tiles"`
+
+	// Tile properties by tile ID, as written by Tiled before 1.2
+	TileProperties map[string]map[string]interface{} `json:"tileproperties"`
+}
+
+// The tiles of a tileset, stored as an array since Tiled 1.2 and as an
+// object by tile ID before
+type jsonTiles []jsonTile
+
+type jsonTile struct {
+	ID         uint32         `json:"id"`
+	Properties []jsonProperty `json:"properties"`
+	Animation  []jsonFrame    `json:"animation"`
+}
+
+type jsonFrame struct {
+	TileID   uint32 `json:"tileid"`
+	Duration uint32 `json:"duration"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Visible     *bool           `json:"visible"`
+	Opacity     *float64        `json:"opacity"`
+	OffsetX     float64         `json:"offsetx"`
+	OffsetY     float64         `json:"offsety"`
+	Properties  []jsonProperty  `json:"properties"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"` // An array of IDs, or a string if encoded
+	Objects     []jsonObject    `json:"objects"`
+	Layers      []jsonLayer     `json:"layers"` // The layers of a group
+}
+
+type jsonObject struct {
+	ID         int            `json:"id"`
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Class      string         `json:"class"` // The name of Type since Tiled 1.9
+	X          float64        `json:"x"`
+	Y          float64        `json:"y"`
+	Width      float64        `json:"width"`
+	Height     float64        `json:"height"`
+	Rotation   float64        `json:"rotation"`
+	GID        uint32         `json:"gid"`
+	Visible    *bool          `json:"visible"`
+	Ellipse    bool           `json:"ellipse"`
+	Point      bool           `json:"point"`
+	Polygon    []Point        `json:"polygon"`
+	Polyline   []Point        `json:"polyline"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+// Loads a map from a JSON file. External tilesets and images are looked
+// up relative to the directory of the file.
+func LoadJSON(file string) (*Map, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadJSON(f, filepath.Dir(file))
+}
+
+// Reads a map in the JSON format. External tilesets and images are looked
+// up relative to dir.
+func ReadJSON(r io.Reader, dir string) (*Map, error) {
+	var jm jsonMap
+	if err := json.NewDecoder(r).Decode(&jm); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Width:           jm.Width,
+		Height:          jm.Height,
+		TileWidth:       jm.TileWidth,
+		TileHeight:      jm.TileHeight,
+		BackgroundColor: jm.BackgroundColor,
+		Properties:      jsonProperties(jm.Properties),
+	}
+
+	for _, jts := range jm.Tilesets {
+		var ts *Tileset
+		var err error
+		switch {
+		case jts.Source == "":
+			ts = jsonConvertTileset(jts, dir)
+		case strings.HasSuffix(strings.ToLower(jts.Source), ".json"):
+			ts, err = loadJSONTileset(resolve(dir, jts.Source))
+		default:
+			ts, err = tmxLoadTileset(tmxTileset{Source: jts.Source}, dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		ts.FirstGID = jts.FirstGID
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	if err := m.addJSONLayers(jm.Layers, 0, 0, 1, true); err != nil {
+		return nil, err
+	}
+
+	if err := m.check(jm.Orientation, jm.Infinite); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Adds layers to the map, flattening groups. The offset, opacity and
+// visibility of groups apply to the layers in them.
+func (m *Map) addJSONLayers(layers []jsonLayer, offsetX, offsetY, opacity float64, visible bool) error {
+	for _, jl := range layers {
+		x, y := offsetX+jl.OffsetX, offsetY+jl.OffsetY
+		o := opacity
+		if jl.Opacity != nil {
+			o *= *jl.Opacity
+		}
+		v := visible && ((jl.Visible == nil) || *jl.Visible)
+
+		switch jl.Type {
+		case "tilelayer":
+			l := &TileLayer{
+				Name:       jl.Name,
+				Visible:    v,
+				Opacity:    o,
+				OffsetX:    int(math.Floor(x + 0.5)),
+				OffsetY:    int(math.Floor(y + 0.5)),
+				Properties: jsonProperties(jl.Properties),
+			}
+
+			var err error
+			if jl.Encoding == "base64" {
+				var text string
+				if err = json.Unmarshal(jl.Data, &text); err == nil {
+					l.Tiles, err = decodeData(jl.Encoding, jl.Compression, text)
+				}
+			} else {
+				err = json.Unmarshal(jl.Data, &l.Tiles)
+			}
+			if err != nil {
+				return err
+			}
+
+			m.Layers = append(m.Layers, l)
+
+		case "objectgroup":
+			g := &ObjectGroup{
+				Name:       jl.Name,
+				Visible:    v,
+				OffsetX:    int(math.Floor(x + 0.5)),
+				OffsetY:    int(math.Floor(y + 0.5)),
+				Properties: jsonProperties(jl.Properties),
+			}
+			for _, jo := range jl.Objects {
+				o := &Object{
+					ID:         jo.ID,
+					Name:       jo.Name,
+					Type:       jo.Type,
+					X:          jo.X,
+					Y:          jo.Y,
+					Width:      jo.Width,
+					Height:     jo.Height,
+					Rotation:   jo.Rotation,
+					GID:        jo.GID,
+					Visible:    (jo.Visible == nil) || *jo.Visible,
+					Ellipse:    jo.Ellipse,
+					Point:      jo.Point,
+					Polygon:    jo.Polygon,
+					Polyline:   jo.Polyline,
+					Properties: jsonProperties(jo.Properties),
+				}
+				if o.Type == "" {
+					o.Type = jo.Class
+				}
+				g.Objects = append(g.Objects, o)
+			}
+			m.ObjectGroups = append(m.ObjectGroups, g)
+
+		case "group":
+			if err := m.addJSONLayers(jl.Layers, x, y, o, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *jsonTiles) UnmarshalJSON(data []byte) error {
+	var tiles []jsonTile
+	if err := json.Unmarshal(data, &tiles); err == nil {
+		*t = tiles
+		return nil
+	}
+
+	var byID map[string]jsonTile
+	if err := json.Unmarshal(data, &byID); err != nil {
+		return err
+	}
+	ids := make([]int, 0, len(byID))
+	for key := range byID {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			return errors.New("tilemap: invalid tile ID \"" + key + "\"")
+		}
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		tile := byID[strconv.Itoa(id)]
+		tile.ID = uint32(id)
+		*t = append(*t, tile)
+	}
+	return nil
+}
+
+// Loads an external tileset from a JSON file.
+func loadJSONTileset(file string) (*Tileset, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var jts jsonTileset
+	if err := json.NewDecoder(f).Decode(&jts); err != nil {
+		return nil, err
+	}
+	return jsonConvertTileset(jts, filepath.Dir(file)), nil
+}
+
+func jsonConvertTileset(jts jsonTileset, dir string) *Tileset {
+	ts := &Tileset{
+		FirstGID:         jts.FirstGID,
+		Name:             jts.Name,
+		TileWidth:        jts.TileWidth,
+		TileHeight:       jts.TileHeight,
+		Spacing:          jts.Spacing,
+		Margin:           jts.Margin,
+		TileCount:        jts.TileCount,
+		Columns:          jts.Columns,
+		Image:            resolve(dir, jts.Image),
+		ImageWidth:       jts.ImageWidth,
+		ImageHeight:      jts.ImageHeight,
+		TransparentColor: strings.TrimPrefix(jts.TransparentColor, "#"),
+		Animations:       make(map[uint32][]Frame),
+		TileProperties:   make(map[uint32]Properties),
+	}
+
+	for _, t := range jts.Tiles {
+		if len(t.Animation) > 0 {
+			frames := make([]Frame, len(t.Animation))
+			for i, f := range t.Animation {
+				frames[i] = Frame{f.TileID, f.Duration}
+			}
+			ts.Animations[t.ID] = frames
+		}
+		if len(t.Properties) > 0 {
+			ts.TileProperties[t.ID] = jsonProperties(t.Properties)
+		}
+	}
+	for key, props := range jts.TileProperties {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil {
+			continue
+		}
+		p := make(Properties, len(props))
+		for name, value := range props {
+			p[name] = jsonValue(value)
+		}
+		ts.TileProperties[uint32(id)] = p
+	}
+
+	return ts
+}
+
+func jsonProperties(props []jsonProperty) Properties {
+	if len(props) == 0 {
+		return nil
+	}
+
+	p := make(Properties, len(props))
+	for _, prop := range props {
+		p[prop.Name] = jsonValue(prop.Value)
+	}
+	return p
+}
+
+// Returns a property value as text.
+func jsonValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
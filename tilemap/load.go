@@ -0,0 +1,112 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Returns the path of a file referenced by a map or tileset in dir.
+func resolve(dir, file string) string {
+	if (file == "") || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(dir, file)
+}
+
+// Decodes tile layer data stored as CSV or as base64, which may be
+// compressed.
+func decodeData(encoding, compression, text string) ([]uint32, error) {
+	switch encoding {
+	case "csv":
+		return decodeCSV(text)
+	case "base64":
+	default:
+		return nil, errors.New("tilemap: unsupported layer data encoding \"" + encoding + "\"")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(text))
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	switch compression {
+	case "":
+	case "gzip":
+		if r, err = gzip.NewReader(r); err != nil {
+			return nil, err
+		}
+	case "zlib":
+		if r, err = zlib.NewReader(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("tilemap: unsupported layer data compression \"" + compression + "\"")
+	}
+
+	if data, err = ioutil.ReadAll(r); err != nil {
+		return nil, err
+	}
+	if len(data)%4 != 0 {
+		return nil, errors.New("tilemap: truncated layer data")
+	}
+
+	tiles := make([]uint32, len(data)/4)
+	for i := range tiles {
+		tiles[i] = binary.LittleEndian.Uint32(data[4*i:])
+	}
+	return tiles, nil
+}
+
+func decodeCSV(text string) ([]uint32, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return (r == ',') || (r == ' ') || (r == '\n') || (r == '\r') || (r == '\t')
+	})
+
+	tiles := make([]uint32, len(fields))
+	for i, field := range fields {
+		gid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, errors.New("tilemap: invalid tile in CSV layer data: " + field)
+		}
+		tiles[i] = uint32(gid)
+	}
+	return tiles, nil
+}
+
+// Checks the map for things which are not supported, and prepares its
+// layers for use.
+func (m *Map) check(orientation string, infinite bool) error {
+	if (orientation != "") && (orientation != "orthogonal") {
+		return errors.New("tilemap: unsupported orientation \"" + orientation + "\", only orthogonal maps are supported")
+	}
+	if infinite {
+		return errors.New("tilemap: infinite maps are not supported")
+	}
+	if (m.Width <= 0) || (m.Height <= 0) || (m.TileWidth <= 0) || (m.TileHeight <= 0) {
+		return errors.New("tilemap: invalid map size")
+	}
+
+	for _, l := range m.Layers {
+		if len(l.Tiles) != m.Width*m.Height {
+			return errors.New("tilemap: layer \"" + l.Name + "\" does not have one tile per cell")
+		}
+		l.Width = m.Width
+	}
+	for _, ts := range m.Tilesets {
+		if ts.Image == "" {
+			return errors.New("tilemap: tileset \"" + ts.Name + "\" has no image; image collection tilesets are not supported")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+/*
+Package tilemap loads maps made with the Tiled map editor (http://www.mapeditor.org)
+and draws them onto SDL surfaces.
+
+Maps are loaded from TMX (XML) files with LoadTMX and from JSON files with
+LoadJSON. Tilesets may be embedded in the map or stored in external TSX or
+JSON files. Tile layers, flipped tiles, animated tiles and object layers are
+supported, for orthogonal maps. Tile layer data may be stored as XML, CSV
+or base64, uncompressed or compressed with gzip or zlib.
+
+A Renderer draws the visible part of the map through a Camera:
+
+	m, err := tilemap.LoadTMX("level1.tmx")
+	...
+	renderer := tilemap.NewRenderer(m)
+	camera := &tilemap.Camera{Viewport: sdl.Rect{W: 640, H: 480}}
+	for {
+		camera.CenterOn(playerX, playerY, m)
+		renderer.Draw(screen, camera, sdl.GetTicks())
+		screen.Flip()
+	}
+*/
+package tilemap
+
+// Flags stored in the upper bits of global tile IDs. A tile is flipped
+// diagonally (its x and y axes swapped) before it is flipped horizontally
+// and vertically.
+const (
+	FLIPPED_HORIZONTALLY = 0x80000000
+	FLIPPED_VERTICALLY   = 0x40000000
+	FLIPPED_DIAGONALLY   = 0x20000000
+
+	flipMask = FLIPPED_HORIZONTALLY | FLIPPED_VERTICALLY | FLIPPED_DIAGONALLY
+)
+
+// A map made of layers of tiles and objects.
+type Map struct {
+	Width, Height         int // The size in tiles
+	TileWidth, TileHeight int // The size of a grid cell in pixels
+
+	Tilesets     []*Tileset
+	Layers       []*TileLayer   // From the bottom to the top
+	ObjectGroups []*ObjectGroup // From the bottom to the top
+
+	BackgroundColor string // As in the map file, for example "#6495ed"; "" if none
+	Properties      Properties
+}
+
+// Custom properties of a map, layer, tile or object. Values of all types
+// are stored as text, booleans as "true" or "false".
+type Properties map[string]string
+
+// The tiles of one image, numbered from left to right and top to bottom.
+type Tileset struct {
+	FirstGID uint32 // The global ID of the first tile
+	Name     string
+
+	TileWidth, TileHeight int
+	Spacing, Margin       int // Between tiles, and around all tiles, in pixels
+	TileCount, Columns    int
+
+	// The image file, relative to the current directory, and its size
+	Image                   string
+	ImageWidth, ImageHeight int
+
+	// The color of the image which is transparent, for example "ff00ff";
+	// "" if none
+	TransparentColor string
+
+	Animations     map[uint32][]Frame    // The animations of tiles, by local tile ID
+	TileProperties map[uint32]Properties // The properties of tiles, by local tile ID
+}
+
+// One frame of an animated tile.
+type Frame struct {
+	TileID   uint32 // The local ID of the tile shown in this frame
+	Duration uint32 // In milliseconds
+}
+
+// A layer of tiles on the grid of the map.
+type TileLayer struct {
+	Name             string
+	Visible          bool
+	Opacity          float64 // From 0 to 1
+	OffsetX, OffsetY int     // Drawing offset in pixels
+
+	// Global tile IDs, including flip flags, row by row. 0 is an empty cell.
+	Tiles []uint32
+	Width int // The number of tiles in a row, the width of the map
+
+	Properties Properties
+}
+
+// Creates a visible, opaque and empty layer of width by height tiles.
+func NewTileLayer(name string, width, height int) *TileLayer {
+	return &TileLayer{
+		Name:    name,
+		Visible: true,
+		Opacity: 1,
+		Tiles:   make([]uint32, width*height),
+		Width:   width,
+	}
+}
+
+// Returns the global tile ID of the cell x, y, including flip flags, or 0 if
+// the cell is empty or outside of the layer.
+func (l *TileLayer) Tile(x, y int) uint32 {
+	if (x < 0) || (y < 0) || (x >= l.Width) || (y*l.Width+x >= len(l.Tiles)) {
+		return 0
+	}
+	return l.Tiles[y*l.Width+x]
+}
+
+// Sets the global tile ID of the cell x, y. Cells outside of the layer are
+// ignored. A Renderer which draws the layer must be invalidated afterwards.
+func (l *TileLayer) SetTile(x, y int, gid uint32) {
+	if (x < 0) || (y < 0) || (x >= l.Width) || (y*l.Width+x >= len(l.Tiles)) {
+		return
+	}
+	l.Tiles[y*l.Width+x] = gid
+}
+
+// A layer of objects, which are not drawn.
+type ObjectGroup struct {
+	Name             string
+	Visible          bool
+	OffsetX, OffsetY int
+	Objects          []*Object
+	Properties       Properties
+}
+
+// An object of an object layer, for example a spawn point or a trigger area.
+type Object struct {
+	ID            int
+	Name, Type    string
+	X, Y          float64 // In pixels; the bottom-left corner for tile objects
+	Width, Height float64
+	Rotation      float64 // In degrees, clockwise
+	GID           uint32  // The global tile ID of tile objects, 0 for others
+	Visible       bool
+
+	Ellipse  bool    // Whether the object is an ellipse inside its bounds
+	Point    bool    // Whether the object is a single point
+	Polygon  []Point // The points of a polygon, relative to X, Y
+	Polyline []Point // The points of a polyline, relative to X, Y
+
+	Properties Properties
+}
+
+// A point of a polygon or polyline.
+type Point struct {
+	X, Y float64
+}
+
+// Returns the tileset containing the tile with the global ID gid, flip
+// flags ignored, or nil if there is none.
+func (m *Map) TilesetFor(gid uint32) *Tileset {
+	gid &^= flipMask
+	if gid == 0 {
+		return nil
+	}
+
+	var found *Tileset
+	for _, ts := range m.Tilesets {
+		if (ts.FirstGID <= gid) && ((found == nil) || (ts.FirstGID > found.FirstGID)) {
+			found = ts
+		}
+	}
+	return found
+}
+
+// Returns the layer with the given name, or nil if there is none.
+func (m *Map) Layer(name string) *TileLayer {
+	for _, l := range m.Layers {
+		if l.Name == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// Returns the object group with the given name, or nil if there is none.
+func (m *Map) ObjectGroup(name string) *ObjectGroup {
+	for _, g := range m.ObjectGroups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Returns the number of columns of tiles in the image.
+func (ts *Tileset) columns() int {
+	if ts.Columns > 0 {
+		return ts.Columns
+	}
+	if ts.TileWidth+ts.Spacing <= 0 {
+		return 0
+	}
+	return (ts.ImageWidth - 2*ts.Margin + ts.Spacing) / (ts.TileWidth + ts.Spacing)
+}
+
+// Returns the position of a tile in the image.
+func (ts *Tileset) tilePosition(id uint32) (x, y int) {
+	columns := ts.columns()
+	if columns <= 0 {
+		return 0, 0
+	}
+	col, row := int(id)%columns, int(id)/columns
+	return ts.Margin + col*(ts.TileWidth+ts.Spacing), ts.Margin + row*(ts.TileHeight+ts.Spacing)
+}
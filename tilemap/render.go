@@ -0,0 +1,598 @@
+package tilemap
+
+import (
+	"errors"
+	"image"
+	"sdl"
+	"strconv"
+	"strings"
+)
+
+// Draws the tile layers of a map.
+//
+// The images of the tilesets are converted to 32-bit surfaces with alpha.
+// Tile layers are drawn in chunks of tiles, which are cached in surfaces of
+// the same format, so that each frame only needs a few blits per layer.
+// Animated tiles are not cached and are drawn one by one.
+type Renderer struct {
+	Map *Map
+
+	// The width and height of a chunk in tiles. If 0, every tile is drawn
+	// in every frame, which uses less memory but is slower. Call Invalidate
+	// after changing it.
+	ChunkSize int
+
+	// The number of cached chunks above which chunks not drawn in the last
+	// frame are freed. If 0, chunks are kept until Invalidate or Free.
+	MaxChunks int
+
+	sheets   map[*Tileset]*sdl.Surface
+	flipped  map[uint32]*sdl.Surface // Flipped tiles, by global tile ID with flip flags
+	chunks   map[chunkKey]*chunk
+	animated map[*TileLayer][]int // The indexes of the animated tiles of layers
+	scratch  *sdl.Surface         // For drawing single translucent tiles
+
+	// How far tiles larger than the grid of the map extend beyond their
+	// cells; to the right and to the top
+	extraW, extraH int
+
+	frame uint64 // The number of the current call of Draw
+}
+
+type chunkKey struct {
+	layer *TileLayer
+	x, y  int // In chunks
+}
+
+type chunk struct {
+	surface *sdl.Surface
+	frame   uint64 // When it was last drawn
+}
+
+// Creates a renderer for the map with chunks of 16x16 tiles, of which at
+// most 256 are kept if not shown.
+func NewRenderer(m *Map) *Renderer {
+	return &Renderer{
+		Map:       m,
+		ChunkSize: 16,
+		MaxChunks: 256,
+	}
+}
+
+// Loads the images of the tilesets, unless they are already loaded. Draw
+// calls it on first use; calling it before allows to handle errors.
+func (r *Renderer) LoadImages() error {
+	if r.sheets != nil {
+		return nil
+	}
+
+	sheets := make(map[*Tileset]*sdl.Surface)
+	maxSize := 0
+	for _, ts := range r.Map.Tilesets {
+		sheet, err := loadSheet(ts)
+		if err != nil {
+			for _, s := range sheets {
+				s.Free()
+			}
+			return err
+		}
+		sheets[ts] = sheet
+
+		// Diagonally flipped tiles swap their width and height
+		if ts.TileWidth > maxSize {
+			maxSize = ts.TileWidth
+		}
+		if ts.TileHeight > maxSize {
+			maxSize = ts.TileHeight
+		}
+	}
+
+	r.sheets = sheets
+	r.flipped = make(map[uint32]*sdl.Surface)
+	r.chunks = make(map[chunkKey]*chunk)
+	r.animated = make(map[*TileLayer][]int)
+	r.extraW = maxInt(maxSize-r.Map.TileWidth, 0)
+	r.extraH = maxInt(maxSize-r.Map.TileHeight, 0)
+	return nil
+}
+
+// Loads the image of a tileset as a 32-bit surface with alpha, in which the
+// transparent color of the tileset has an alpha of 0.
+func loadSheet(ts *Tileset) (*sdl.Surface, error) {
+	img := sdl.Load(ts.Image)
+	if img == nil {
+		return nil, errors.New("tilemap: " + ts.Image + ": " + sdl.GetError())
+	}
+	sheet := img.ConvertSurface(sdl.FormatARGB8888(), sdl.SRCALPHA)
+	img.Free()
+	if sheet == nil {
+		return nil, errors.New("tilemap: " + ts.Image + ": " + sdl.GetError())
+	}
+
+	if ts.TransparentColor == "" {
+		return sheet, nil
+	}
+	key, err := strconv.ParseUint(ts.TransparentColor, 16, 32)
+	if err != nil {
+		sheet.Free()
+		return nil, errors.New("tilemap: invalid transparent color \"" + ts.TransparentColor + "\" of tileset \"" + ts.Name + "\"")
+	}
+
+	if sheet.Lock() == 0 {
+		for y := 0; y < int(sheet.H); y++ {
+			row := sheet.Uint32Row(y)
+			for x, p := range row {
+				if p&0xffffff == uint32(key) {
+					row[x] = 0
+				}
+			}
+		}
+		sheet.Unlock()
+	}
+	return sheet, nil
+}
+
+// Frees the cached chunks. Call it after changing tiles of the map.
+func (r *Renderer) Invalidate() {
+	for _, c := range r.chunks {
+		c.surface.Free()
+	}
+	if r.sheets != nil {
+		r.chunks = make(map[chunkKey]*chunk)
+		r.animated = make(map[*TileLayer][]int)
+	}
+}
+
+// Frees the cached chunk containing the cell x, y of the layer. Call it
+// after changing the tile of the cell; it is cheaper than Invalidate.
+func (r *Renderer) InvalidateCell(l *TileLayer, x, y int) {
+	if (r.ChunkSize <= 0) || (x < 0) || (y < 0) {
+		return
+	}
+
+	key := chunkKey{l, x / r.ChunkSize, y / r.ChunkSize}
+	if c, ok := r.chunks[key]; ok {
+		c.surface.Free()
+		delete(r.chunks, key)
+	}
+	delete(r.animated, l)
+}
+
+// Frees all surfaces of the renderer, including the images of the tilesets.
+// The renderer can still be used; the images are loaded again when needed.
+func (r *Renderer) Free() {
+	r.Invalidate()
+	for _, s := range r.flipped {
+		s.Free()
+	}
+	for _, s := range r.sheets {
+		s.Free()
+	}
+	if r.scratch != nil {
+		r.scratch.Free()
+	}
+	r.sheets, r.flipped, r.chunks, r.animated, r.scratch = nil, nil, nil, nil, nil
+}
+
+// Draws the visible tile layers of the map through the camera onto dst,
+// with the animated tiles as they are at the time ticks, in milliseconds.
+// The background color of the map, if any, is drawn first.
+// Returns 0 if successful or -1 on error.
+func (r *Renderer) Draw(dst *sdl.Surface, cam *Camera, ticks uint32) int {
+	if err := r.LoadImages(); err != nil {
+		sdl.SetError(err.Error())
+		return -1
+	}
+
+	vp := cam.Viewport
+	if (vp.W == 0) || (vp.H == 0) {
+		vp = sdl.Rect{W: uint16(dst.W), H: uint16(dst.H)}
+	}
+	var clip sdl.Rect
+	dst.GetClipRect(&clip)
+	vp = vp.Intersect(clip)
+	if vp.Empty() {
+		return 0
+	}
+	dst.SetClipRect(&vp)
+	defer dst.SetClipRect(&clip)
+
+	ret := 0
+	if color, ok := parseColor(r.Map.BackgroundColor); ok {
+		fill := vp
+		ret = dst.FillRect(&fill, sdl.MapRGBA(dst.Format, uint8(color>>16), uint8(color>>8), uint8(color), 255))
+	}
+
+	r.frame++
+	for _, l := range r.Map.Layers {
+		if !l.Visible || (l.Opacity <= 0) {
+			continue
+		}
+
+		// The position of the top-left corner of the layer on dst
+		ox := int(vp.X) - cam.X + l.OffsetX
+		oy := int(vp.Y) - cam.Y + l.OffsetY
+
+		var err int
+		if r.ChunkSize > 0 {
+			err = r.drawChunks(dst, l, vp, ox, oy, ticks)
+		} else {
+			err = r.drawTiles(dst, l, vp, ox, oy, ticks)
+		}
+		if err != 0 {
+			ret = -1
+		}
+	}
+
+	r.evict()
+	return ret
+}
+
+// Draws all visible tiles of the layer one by one.
+func (r *Renderer) drawTiles(dst *sdl.Surface, l *TileLayer, vp sdl.Rect, ox, oy int, ticks uint32) int {
+	m := r.Map
+	x0, y0, x1, y1 := cellRange(vp, ox, oy, m.TileWidth, m.TileHeight, r.extraW, r.extraH, m.Width, m.Height)
+
+	ret := 0
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			gid := l.Tile(x, y)
+			if gid == 0 {
+				continue
+			}
+			if r.drawTile(dst, gid, ox+x*m.TileWidth, oy+y*m.TileHeight, l.Opacity, ticks) != 0 {
+				ret = -1
+			}
+		}
+	}
+	return ret
+}
+
+// Draws the visible chunks of the layer, creating them if needed, and then
+// the visible animated tiles of the layer.
+func (r *Renderer) drawChunks(dst *sdl.Surface, l *TileLayer, vp sdl.Rect, ox, oy int, ticks uint32) int {
+	m := r.Map
+	cs := r.ChunkSize
+	cw, ch := cs*m.TileWidth, cs*m.TileHeight
+	cols, rows := (m.Width+cs-1)/cs, (m.Height+cs-1)/cs
+	x0, y0, x1, y1 := cellRange(vp, ox, oy, cw, ch, r.extraW, r.extraH, cols, rows)
+
+	ret := 0
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			key := chunkKey{l, x, y}
+			c := r.chunks[key]
+			if c == nil {
+				s := r.createChunk(l, x, y)
+				if s == nil {
+					ret = -1
+					continue
+				}
+				c = &chunk{surface: s}
+				r.chunks[key] = c
+			}
+			c.frame = r.frame
+
+			pos := sdl.Rect{X: int16(ox + x*cw), Y: int16(oy + y*ch - r.extraH)}
+			if dst.Blit(&pos, c.surface, nil) != 0 {
+				ret = -1
+			}
+		}
+	}
+
+	x0, y0, x1, y1 = cellRange(vp, ox, oy, m.TileWidth, m.TileHeight, r.extraW, r.extraH, m.Width, m.Height)
+	for _, i := range r.animatedTiles(l) {
+		x, y := i%l.Width, i/l.Width
+		if (x < x0) || (x >= x1) || (y < y0) || (y >= y1) {
+			continue
+		}
+		if r.drawTile(dst, l.Tiles[i], ox+x*m.TileWidth, oy+y*m.TileHeight, l.Opacity, ticks) != 0 {
+			ret = -1
+		}
+	}
+	return ret
+}
+
+// Creates the chunk x, y of the layer, with all tiles except animated ones
+// and with the opacity of the layer applied. The chunk has room for tiles
+// extending beyond it to the right and to the top. Returns nil on error.
+func (r *Renderer) createChunk(l *TileLayer, x, y int) *sdl.Surface {
+	m := r.Map
+	cs := r.ChunkSize
+	s := createSurface(cs*m.TileWidth+r.extraW, cs*m.TileHeight+r.extraH)
+	if s == nil {
+		return nil
+	}
+
+	for row := 0; row < cs; row++ {
+		for col := 0; col < cs; col++ {
+			gid := l.Tile(x*cs+col, y*cs+row)
+			if (gid == 0) || r.isAnimated(gid) {
+				continue
+			}
+			src, area := r.tile(gid, 0)
+			if src == nil {
+				continue
+			}
+			tx := col * m.TileWidth
+			ty := r.extraH + (row+1)*m.TileHeight - int(area.H)
+			compose(s, tx, ty, src, area, l.Opacity)
+		}
+	}
+	return s
+}
+
+// Returns the indexes of the animated tiles of the layer.
+func (r *Renderer) animatedTiles(l *TileLayer) []int {
+	tiles, ok := r.animated[l]
+	if !ok && (l.Width > 0) {
+		for i, gid := range l.Tiles {
+			if (gid != 0) && r.isAnimated(gid) {
+				tiles = append(tiles, i)
+			}
+		}
+		r.animated[l] = tiles
+	}
+	return tiles
+}
+
+// Frees chunks which were not drawn in this frame while there are more than
+// MaxChunks.
+func (r *Renderer) evict() {
+	if (r.MaxChunks <= 0) || (len(r.chunks) <= r.MaxChunks) {
+		return
+	}
+	for key, c := range r.chunks {
+		if c.frame == r.frame {
+			continue
+		}
+		c.surface.Free()
+		delete(r.chunks, key)
+		if len(r.chunks) <= r.MaxChunks {
+			return
+		}
+	}
+}
+
+// Draws the tile gid with the top-left corner of its cell at x, y.
+// Tiles of a different size than the cells are aligned to the bottom-left
+// corner of the cell.
+func (r *Renderer) drawTile(dst *sdl.Surface, gid uint32, x, y int, opacity float64, ticks uint32) int {
+	src, area := r.tile(gid, ticks)
+	if src == nil {
+		return 0
+	}
+	pos := sdl.Rect{X: int16(x), Y: int16(y + r.Map.TileHeight - int(area.H))}
+
+	if opacity >= 1 {
+		return dst.Blit(&pos, src, &area)
+	}
+
+	// SDL cannot blend a surface with both an alpha channel and an
+	// opacity, so the opacity is applied to a copy of the tile
+	if (r.scratch == nil) || (int(r.scratch.W) < int(area.W)) || (int(r.scratch.H) < int(area.H)) {
+		if r.scratch != nil {
+			r.scratch.Free()
+		}
+		if r.scratch = createSurface(int(area.W), int(area.H)); r.scratch == nil {
+			return -1
+		}
+	}
+	r.scratch.FillRect(nil, 0)
+	compose(r.scratch, 0, 0, src, area, opacity)
+	return dst.Blit(&pos, r.scratch, &sdl.Rect{W: area.W, H: area.H})
+}
+
+// Returns the surface and the area of it showing the tile gid at the time
+// ticks, or nil if the tile does not exist.
+func (r *Renderer) tile(gid uint32, ticks uint32) (*sdl.Surface, sdl.Rect) {
+	ts := r.Map.TilesetFor(gid)
+	if ts == nil {
+		return nil, sdl.Rect{}
+	}
+	sheet := r.sheets[ts]
+	flags := gid & flipMask
+	id := gid&^flipMask - ts.FirstGID
+
+	if frames := ts.Animations[id]; len(frames) > 0 {
+		id = frameAt(frames, ticks)
+	}
+
+	x, y := ts.tilePosition(id)
+	if (ts.TileWidth <= 0) || (ts.TileHeight <= 0) ||
+		(x+ts.TileWidth > int(sheet.W)) || (y+ts.TileHeight > int(sheet.H)) {
+		return nil, sdl.Rect{}
+	}
+	area := sdl.Rect{X: int16(x), Y: int16(y), W: uint16(ts.TileWidth), H: uint16(ts.TileHeight)}
+
+	if flags == 0 {
+		return sheet, area
+	}
+	s := r.flippedTile((ts.FirstGID+id)|flags, sheet, area)
+	if s == nil {
+		return nil, sdl.Rect{}
+	}
+	return s, sdl.Rect{W: uint16(s.W), H: uint16(s.H)}
+}
+
+// Returns whether the tile gid is animated.
+func (r *Renderer) isAnimated(gid uint32) bool {
+	ts := r.Map.TilesetFor(gid)
+	return (ts != nil) && (len(ts.Animations[gid&^flipMask-ts.FirstGID]) > 0)
+}
+
+// Returns the local ID of the tile shown by the animation at the time ticks.
+func frameAt(frames []Frame, ticks uint32) uint32 {
+	var total uint32
+	for _, f := range frames {
+		total += f.Duration
+	}
+	if total == 0 {
+		return frames[0].TileID
+	}
+
+	t := ticks % total
+	for _, f := range frames {
+		if t < f.Duration {
+			return f.TileID
+		}
+		t -= f.Duration
+	}
+	return frames[len(frames)-1].TileID
+}
+
+// Returns a surface with the area of the sheet flipped as given by the flags
+// of gid. The surface is created on first use and cached.
+func (r *Renderer) flippedTile(gid uint32, sheet *sdl.Surface, area sdl.Rect) *sdl.Surface {
+	if s, ok := r.flipped[gid]; ok {
+		return s
+	}
+
+	w, h := int(area.W), int(area.H)
+	if gid&FLIPPED_DIAGONALLY != 0 {
+		w, h = h, w
+	}
+	s := createSurface(w, h)
+	if s == nil {
+		return nil
+	}
+
+	if s.Lock() == 0 {
+		if sheet.Lock() == 0 {
+			for y := 0; y < h; y++ {
+				row := s.Uint32Row(y)
+				for x := range row {
+					// Undo the flips in the reverse order
+					sx, sy := x, y
+					if gid&FLIPPED_HORIZONTALLY != 0 {
+						sx = w - 1 - sx
+					}
+					if gid&FLIPPED_VERTICALLY != 0 {
+						sy = h - 1 - sy
+					}
+					if gid&FLIPPED_DIAGONALLY != 0 {
+						sx, sy = sy, sx
+					}
+					row[x] = sheet.Uint32Row(int(area.Y) + sy)[int(area.X)+sx]
+				}
+			}
+			sheet.Unlock()
+		}
+		s.Unlock()
+	}
+
+	r.flipped[gid] = s
+	return s
+}
+
+// Creates a transparent surface in the format of the tileset images.
+func createSurface(w, h int) *sdl.Surface {
+	f := sdl.FormatARGB8888()
+	s := sdl.CreateRGBSurface(sdl.SWSURFACE|sdl.SRCALPHA, w, h, 32, f.Rmask, f.Gmask, f.Bmask, f.Amask)
+	if s != nil {
+		s.FillRect(nil, 0)
+	}
+	return s
+}
+
+// Draws the area of src onto dst at x, y, blending it over the pixels of dst
+// with the given opacity. Both surfaces must be in the format of the tileset
+// images. Unlike a blit, this also blends the alpha channels, so that tiles
+// can be drawn onto transparent surfaces.
+func compose(dst *sdl.Surface, x, y int, src *sdl.Surface, area sdl.Rect, opacity float64) {
+	r := image.Rect(x, y, x+int(area.W), y+int(area.H)).Intersect(image.Rect(0, 0, int(dst.W), int(dst.H)))
+	if r.Empty() {
+		return
+	}
+	if dst.Lock() != 0 {
+		return
+	}
+	defer dst.Unlock()
+	if src.Lock() != 0 {
+		return
+	}
+	defer src.Unlock()
+
+	o := uint32(opacity*255 + 0.5)
+	if o > 255 {
+		o = 255
+	}
+	for dy := r.Min.Y; dy < r.Max.Y; dy++ {
+		drow := dst.Uint32Row(dy)
+		srow := src.Uint32Row(int(area.Y) + dy - y)
+		for dx := r.Min.X; dx < r.Max.X; dx++ {
+			drow[dx] = over(drow[dx], srow[int(area.X)+dx-x], o)
+		}
+	}
+}
+
+// Blends the ARGB pixel src with the given opacity over the ARGB pixel dst.
+func over(dst, src, opacity uint32) uint32 {
+	sa := (src >> 24) * opacity / 255
+	if sa == 0 {
+		return dst
+	}
+	da := dst >> 24
+	if (sa == 255) || (da == 0) {
+		return src&0xffffff | sa<<24
+	}
+
+	da = da * (255 - sa) / 255
+	a := sa + da
+	p := a << 24
+	for shift := uint(0); shift < 24; shift += 8 {
+		c := ((src>>shift)&0xff)*sa + ((dst>>shift)&0xff)*da
+		p |= (c / a) << shift
+	}
+	return p
+}
+
+// Returns the range of cells of a grid of cells of size w, h starting at
+// ox, oy whose contents may be visible in vp, if they extend extraW beyond
+// the cell to the right and extraH to the top. The range is limited to the
+// cols, rows cells of the grid.
+func cellRange(vp sdl.Rect, ox, oy, w, h, extraW, extraH, cols, rows int) (x0, y0, x1, y1 int) {
+	x0 = floorDiv(int(vp.X)-ox-extraW, w)
+	y0 = floorDiv(int(vp.Y)-oy, h)
+	x1 = floorDiv(int(vp.X)+int(vp.W)-1-ox, w) + 1
+	y1 = floorDiv(int(vp.Y)+int(vp.H)-1-oy+extraH, h) + 1
+	return clamp(x0, cols), clamp(y0, rows), clamp(x1, cols), clamp(y1, rows)
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func clamp(v, n int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > n {
+		return n
+	}
+	return v
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Parses a color as "#rrggbb" or "#aarrggbb", ignoring the alpha.
+func parseColor(s string) (uint32, bool) {
+	s = strings.TrimPrefix(s, "#")
+	if (len(s) != 6) && (len(s) != 8) {
+		return 0, false
+	}
+	c, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(c) & 0xffffff, true
+}
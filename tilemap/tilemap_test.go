@@ -0,0 +1,390 @@
+package tilemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Encodes tiles as stored in base64 layer data with the given compression.
+func encodeTiles(t *testing.T, tiles []uint32, compression string) string {
+	raw := make([]byte, 4*len(tiles))
+	for i, gid := range tiles {
+		binary.LittleEndian.PutUint32(raw[4*i:], gid)
+	}
+
+	var buf bytes.Buffer
+	switch compression {
+	case "":
+		buf.Write(raw)
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		w.Write(raw)
+		w.Close()
+	case "zlib":
+		w := zlib.NewWriter(&buf)
+		w.Write(raw)
+		w.Close()
+	default:
+		t.Fatalf("unknown compression %q", compression)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestDecodeData(t *testing.T) {
+	tiles := []uint32{1, 0, 42, FLIPPED_HORIZONTALLY | 3, FLIPPED_DIAGONALLY | FLIPPED_VERTICALLY | 7}
+
+	tests := []struct {
+		name                  string
+		encoding, compression string
+		text                  string
+		want                  []uint32
+		err                   bool
+	}{
+		{"csv", "csv", "", "1,0,42,\n2147483651,\r\n1610612743\n", tiles, false},
+		{"csv with spaces", "csv", "", " 1, 2 ,3 ", []uint32{1, 2, 3}, false},
+		{"csv empty", "csv", "", "\n", []uint32{}, false},
+		{"csv invalid", "csv", "", "1,x,3", nil, true},
+		{"csv too large", "csv", "", "4294967296", nil, true},
+		{"base64", "base64", "", "\n  " + encodeTiles(t, tiles, "") + "\n", tiles, false},
+		{"base64 gzip", "base64", "gzip", encodeTiles(t, tiles, "gzip"), tiles, false},
+		{"base64 zlib", "base64", "zlib", encodeTiles(t, tiles, "zlib"), tiles, false},
+		{"base64 invalid", "base64", "", "!!!", nil, true},
+		{"base64 truncated", "base64", "", base64.StdEncoding.EncodeToString([]byte{1, 0, 0, 0, 2}), nil, true},
+		{"gzip invalid", "base64", "gzip", encodeTiles(t, tiles, ""), nil, true},
+		{"zlib invalid", "base64", "zlib", encodeTiles(t, tiles, ""), nil, true},
+		{"unknown compression", "base64", "zstd", encodeTiles(t, tiles, ""), nil, true},
+		{"unknown encoding", "hex", "", "01", nil, true},
+	}
+
+	for _, test := range tests {
+		got, err := decodeData(test.encoding, test.compression, test.text)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %v", test.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Point
+		err  bool
+	}{
+		{"", nil, false},
+		{"0,0", []Point{{0, 0}}, false},
+		{"0,0 16.5,-8 3e1,2", []Point{{0, 0}, {16.5, -8}, {30, 2}}, false},
+		{"  1,2\n3,4 ", []Point{{1, 2}, {3, 4}}, false},
+		{"1,2,3", nil, true},
+		{"1", nil, true},
+		{"a,2", nil, true},
+		{"1,b", nil, true},
+	}
+
+	for _, test := range tests {
+		got, err := tmxParsePoints(test.text)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", test.text, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", test.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %v, want %v", test.text, got, test.want)
+		}
+	}
+}
+
+// Returns a TMX map of 2x2 tiles with a tileset and the given layers.
+func tmxMapWith(layers string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<map version="1.10" orientation="orthogonal" width="2" height="2" tilewidth="16" tileheight="16">
+ <tileset firstgid="1" name="tiles" tilewidth="16" tileheight="16" tilecount="4" columns="2">
+  <image source="tiles.png" width="32" height="32"/>
+ </tileset>
+` + layers + `
+</map>`
+}
+
+func TestTMXLayerData(t *testing.T) {
+	want := []uint32{1, 2, 0, FLIPPED_VERTICALLY | 4}
+	csv := "1,2,\n0,1073741828"
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"tile elements", `<data><tile gid="1"/><tile gid="2"/><tile/><tile gid="1073741828"/></data>`},
+		{"csv", `<data encoding="csv">` + csv + `</data>`},
+		{"base64", `<data encoding="base64">` + encodeTiles(t, want, "") + `</data>`},
+		{"base64 gzip", `<data encoding="base64" compression="gzip">` + encodeTiles(t, want, "gzip") + `</data>`},
+		{"base64 zlib", `<data encoding="base64" compression="zlib">` + encodeTiles(t, want, "zlib") + `</data>`},
+	}
+
+	for _, test := range tests {
+		m, err := ReadTMX(strings.NewReader(tmxMapWith(`<layer name="l" width="2" height="2">`+test.data+`</layer>`)), "")
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		l := m.Layer("l")
+		if (l == nil) || !reflect.DeepEqual(l.Tiles, want) {
+			t.Errorf("%s: got %+v, want tiles %v", test.name, l, want)
+			continue
+		}
+		if (l.Tile(1, 1) != want[3]) || (l.Width != 2) {
+			t.Errorf("%s: Tile(1, 1) = %x, Width = %d", test.name, l.Tile(1, 1), l.Width)
+		}
+	}
+}
+
+func TestTMXGroups(t *testing.T) {
+	layers := `
+ <layer name="a" width="2" height="2"><data encoding="csv">0,0,0,0</data></layer>
+ <group name="g" offsetx="10" offsety="5" opacity="0.5">
+  <objectgroup name="o" offsetx="1"><object id="1" x="2" y="3"/></objectgroup>
+  <layer name="b" width="2" height="2" offsetx="2.5" opacity="0.5"><data encoding="csv">0,0,0,0</data></layer>
+  <group name="hidden" visible="0">
+   <layer name="c" width="2" height="2"><data encoding="csv">0,0,0,0</data></layer>
+  </group>
+ </group>
+ <layer name="d" width="2" height="2" visible="0"><data encoding="csv">0,0,0,0</data></layer>`
+
+	m, err := ReadTMX(strings.NewReader(tmxMapWith(layers)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name             string
+		visible          bool
+		opacity          float64
+		offsetX, offsetY int
+	}{
+		{"a", true, 1, 0, 0},
+		{"b", true, 0.25, 13, 5},
+		{"c", false, 0.5, 10, 5},
+		{"d", false, 1, 0, 0},
+	}
+	if len(m.Layers) != len(want) {
+		t.Fatalf("got %d layers, want %d", len(m.Layers), len(want))
+	}
+	for i, w := range want {
+		l := m.Layers[i]
+		if (l.Name != w.name) || (l.Visible != w.visible) || (l.Opacity != w.opacity) ||
+			(l.OffsetX != w.offsetX) || (l.OffsetY != w.offsetY) {
+			t.Errorf("layer %d: got %+v, want %+v", i, *l, w)
+		}
+	}
+
+	g := m.ObjectGroup("o")
+	if (g == nil) || (g.OffsetX != 11) || (g.OffsetY != 5) || (len(g.Objects) != 1) || (g.Objects[0].X != 2) {
+		t.Errorf("object group: got %+v", g)
+	}
+}
+
+func TestTMXObjects(t *testing.T) {
+	layers := `
+ <objectgroup name="o">
+  <object id="1" name="spawn" class="point" x="1" y="2"><point/></object>
+  <object id="2" type="area" x="0" y="0" width="8" height="4" visible="0"><ellipse/>
+   <properties><property name="n" type="int" value="3"/><property name="text">multi
+line</property></properties>
+  </object>
+  <object id="3" x="5" y="5"><polygon points="0,0 4,0 4,4"/></object>
+  <object id="4" x="5" y="5"><polyline points="0,0 1.5,2"/></object>
+ </objectgroup>`
+
+	m, err := ReadTMX(strings.NewReader(tmxMapWith(layers)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := m.ObjectGroup("o").Objects
+	if len(objects) != 4 {
+		t.Fatalf("got %d objects, want 4", len(objects))
+	}
+
+	if o := objects[0]; (o.Name != "spawn") || (o.Type != "point") || !o.Point || !o.Visible {
+		t.Errorf("object 1: got %+v", *o)
+	}
+	if o := objects[1]; (o.Type != "area") || !o.Ellipse || o.Visible ||
+		(o.Properties["n"] != "3") || (o.Properties["text"] != "multi\nline") {
+		t.Errorf("object 2: got %+v", *o)
+	}
+	if o := objects[2]; !reflect.DeepEqual(o.Polygon, []Point{{0, 0}, {4, 0}, {4, 4}}) {
+		t.Errorf("object 3: got polygon %v", o.Polygon)
+	}
+	if o := objects[3]; !reflect.DeepEqual(o.Polyline, []Point{{0, 0}, {1.5, 2}}) {
+		t.Errorf("object 4: got polyline %v", o.Polyline)
+	}
+
+	if _, err := ReadTMX(strings.NewReader(tmxMapWith(`<objectgroup><object><polygon points="0,0 1"/></object></objectgroup>`)), ""); err == nil {
+		t.Error("expected an error for invalid points")
+	}
+}
+
+func TestTMXExternalTileset(t *testing.T) {
+	dir := t.TempDir()
+	tsx := `<?xml version="1.0" encoding="UTF-8"?>
+<tileset name="ext" tilewidth="8" tileheight="8" spacing="1" margin="2" tilecount="4" columns="2">
+ <image source="img/ext.png" trans="ff00ff" width="20" height="20"/>
+ <tile id="1"><animation><frame tileid="1" duration="100"/><frame tileid="2" duration="50"/></animation></tile>
+</tileset>`
+	if err := os.WriteFile(filepath.Join(dir, "ext.tsx"), []byte(tsx), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ReadTMX(strings.NewReader(tmxMapWith(`<tileset firstgid="5" source="ext.tsx"/>`)), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := m.TilesetFor(FLIPPED_HORIZONTALLY | 6)
+	if (ts == nil) || (ts.Name != "ext") || (ts.FirstGID != 5) {
+		t.Fatalf("got tileset %+v", ts)
+	}
+	if (ts.Image != filepath.Join(dir, "img", "ext.png")) || (ts.TransparentColor != "ff00ff") {
+		t.Errorf("got image %q, transparent color %q", ts.Image, ts.TransparentColor)
+	}
+	if !reflect.DeepEqual(ts.Animations[1], []Frame{{1, 100}, {2, 50}}) {
+		t.Errorf("got animation %v", ts.Animations[1])
+	}
+	if x, y := ts.tilePosition(3); (x != 11) || (y != 11) {
+		t.Errorf("tile 3 at %d, %d, want 11, 11", x, y)
+	}
+	if m.TilesetFor(4).Name != "tiles" {
+		t.Error("gid 4 should belong to the first tileset")
+	}
+}
+
+// Returns a JSON map of 2x1 tiles with the given tileset and layers.
+func jsonMapWith(tileset, layers string) string {
+	return `{"width": 2, "height": 1, "tilewidth": 16, "tileheight": 16, "orientation": "orthogonal",
+		"tilesets": [` + tileset + `], "layers": [` + layers + `]}`
+}
+
+func TestJSONTilesets(t *testing.T) {
+	const header = `"firstgid": 1, "name": "t", "tilewidth": 16, "tileheight": 16, "image": "t.png",
+		"imagewidth": 32, "imageheight": 16, "transparentcolor": "#ff00ff"`
+
+	tests := []struct {
+		name    string
+		tileset string
+	}{
+		{"array", `{` + header + `, "tiles": [
+			{"id": 1, "animation": [{"tileid": 0, "duration": 10}, {"tileid": 1, "duration": 20}]},
+			{"id": 0, "properties": [{"name": "solid", "type": "bool", "value": true}]}]}`},
+		{"hash", `{` + header + `, "tiles": {
+			"1": {"animation": [{"tileid": 0, "duration": 10}, {"tileid": 1, "duration": 20}]}},
+			"tileproperties": {"0": {"solid": true}}}`},
+	}
+
+	for _, test := range tests {
+		m, err := ReadJSON(strings.NewReader(jsonMapWith(test.tileset, "")), "dir")
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		ts := m.Tilesets[0]
+		if (ts.Image != filepath.Join("dir", "t.png")) || (ts.TransparentColor != "ff00ff") {
+			t.Errorf("%s: got image %q, transparent color %q", test.name, ts.Image, ts.TransparentColor)
+		}
+		if !reflect.DeepEqual(ts.Animations[1], []Frame{{0, 10}, {1, 20}}) {
+			t.Errorf("%s: got animation %v", test.name, ts.Animations[1])
+		}
+		if ts.TileProperties[0]["solid"] != "true" {
+			t.Errorf("%s: got properties %v", test.name, ts.TileProperties)
+		}
+	}
+
+	if _, err := ReadJSON(strings.NewReader(jsonMapWith(`{"firstgid": 1, "image": "t.png", "tiles": {"x": {}}}`, "")), ""); err == nil {
+		t.Error("expected an error for an invalid tile ID")
+	}
+}
+
+func TestJSONLayers(t *testing.T) {
+	want := []uint32{FLIPPED_HORIZONTALLY | 1, 2}
+	layers := `
+		{"type": "tilelayer", "name": "array", "data": [2147483649, 2]},
+		{"type": "group", "offsetx": 4, "opacity": 0.5, "visible": false, "layers": [
+			{"type": "tilelayer", "name": "base64", "encoding": "base64", "compression": "zlib",
+			 "data": "` + encodeTiles(t, want, "zlib") + `", "opacity": 0.5},
+			{"type": "objectgroup", "name": "o", "objects": [
+				{"id": 1, "class": "door", "x": 1, "y": 2, "polygon": [{"x": 0, "y": 0}, {"x": 1, "y": 1}],
+				 "properties": [{"name": "to", "type": "string", "value": "level2"}, {"name": "n", "type": "float", "value": 1.5}]}]}]}`
+
+	m, err := ReadJSON(strings.NewReader(jsonMapWith(`{"firstgid": 1, "image": "t.png"}`, layers)), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Layers) != 2 {
+		t.Fatalf("got %d layers, want 2", len(m.Layers))
+	}
+	for _, l := range m.Layers {
+		if !reflect.DeepEqual(l.Tiles, want) {
+			t.Errorf("%s: got tiles %v, want %v", l.Name, l.Tiles, want)
+		}
+	}
+	if l := m.Layer("base64"); l.Visible || (l.Opacity != 0.25) || (l.OffsetX != 4) {
+		t.Errorf("group not applied: got %+v", *l)
+	}
+
+	o := m.ObjectGroup("o").Objects[0]
+	if (o.Type != "door") || (o.Properties["to"] != "level2") || (o.Properties["n"] != "1.5") || (len(o.Polygon) != 2) {
+		t.Errorf("got object %+v", *o)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		tmx  string
+	}{
+		{"isometric", strings.Replace(tmxMapWith(""), "orthogonal", "isometric", 1)},
+		{"infinite", strings.Replace(tmxMapWith(""), `width="2"`, `infinite="1" width="2"`, 1)},
+		{"no size", strings.Replace(tmxMapWith(""), `width="2" height="2"`, "", 1)},
+		{"tile count", tmxMapWith(`<layer name="l"><data encoding="csv">1,2,3</data></layer>`)},
+		{"no image", tmxMapWith(`<tileset firstgid="10" name="collection"><tile id="0"/></tileset>`)},
+	}
+
+	for _, test := range tests {
+		if _, err := ReadTMX(strings.NewReader(test.tmx), ""); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestTileLayer(t *testing.T) {
+	l := NewTileLayer("l", 3, 2)
+	l.SetTile(2, 1, 7)
+	l.SetTile(3, 0, 9)  // Outside
+	l.SetTile(-1, 0, 9) // Outside
+	l.SetTile(0, 2, 9)  // Outside
+
+	if !reflect.DeepEqual(l.Tiles, []uint32{0, 0, 0, 0, 0, 7}) {
+		t.Errorf("got tiles %v", l.Tiles)
+	}
+	if (l.Tile(2, 1) != 7) || (l.Tile(3, 0) != 0) || (l.Tile(0, -1) != 0) || (l.Tile(0, 2) != 0) {
+		t.Error("Tile returned a wrong value")
+	}
+}
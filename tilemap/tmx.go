@@ -0,0 +1,358 @@
+package tilemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type tmxMap struct {
+	Orientation     string        `xml:"orientation,attr"`
+	Width           int           `xml:"width,attr"`
+	Height          int           `xml:"height,attr"`
+	TileWidth       int           `xml:"tilewidth,attr"`
+	TileHeight      int           `xml:"tileheight,attr"`
+	Infinite        int           `xml:"infinite,attr"`
+	BackgroundColor string        `xml:"backgroundcolor,attr"`
+	Properties      []tmxProperty `xml:"properties>property"`
+	Tilesets        []tmxTileset  `xml:"tileset"`
+	Layers          []tmxLayer    `xml:",any"` // In document order
+}
+
+type tmxProperty struct {
+	Name  string  `xml:"name,attr"`
+	Value *string `xml:"value,attr"`
+	Text  string  `xml:",chardata"` // Multi-line values are stored as text
+}
+
+type tmxTileset struct {
+	FirstGID   uint32    `xml:"firstgid,attr"`
+	Source     string    `xml:"source,attr"`
+	Name       string    `xml:"name,attr"`
+	TileWidth  int       `xml:"tilewidth,attr"`
+	TileHeight int       `xml:"tileheight,attr"`
+	Spacing    int       `xml:"spacing,attr"`
+	Margin     int       `xml:"margin,attr"`
+	TileCount  int       `xml:"tilecount,attr"`
+	Columns    int       `xml:"columns,attr"`
+	Image      tmxImage  `xml:"image"`
+	Tiles      []tmxTile `xml:"tile"`
+}
+
+type tmxImage struct {
+	Source string `xml:"source,attr"`
+	Trans  string `xml:"trans,attr"`
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+}
+
+type tmxTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Frames     []tmxFrame    `xml:"animation>frame"`
+}
+
+type tmxFrame struct {
+	TileID   uint32 `xml:"tileid,attr"`
+	Duration uint32 `xml:"duration,attr"`
+}
+
+// A <layer>, <objectgroup> or <group> element, or another element which
+// is ignored
+type tmxLayer struct {
+	XMLName    xml.Name
+	Name       string        `xml:"name,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Opacity    *float64      `xml:"opacity,attr"`
+	OffsetX    float64       `xml:"offsetx,attr"`
+	OffsetY    float64       `xml:"offsety,attr"`
+	Properties []tmxProperty `xml:"properties>property"`
+	Data       tmxData       `xml:"data"`
+	Objects    []tmxObject   `xml:"object"`
+	Layers     []tmxLayer    `xml:",any"` // The layers of a group
+}
+
+type tmxData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+}
+
+type tmxObject struct {
+	ID         int           `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Class      string        `xml:"class,attr"` // The name of Type since Tiled 1.9
+	X          float64       `xml:"x,attr"`
+	Y          float64       `xml:"y,attr"`
+	Width      float64       `xml:"width,attr"`
+	Height     float64       `xml:"height,attr"`
+	Rotation   float64       `xml:"rotation,attr"`
+	GID        uint32        `xml:"gid,attr"`
+	Visible    *int          `xml:"visible,attr"`
+	Ellipse    *struct{}     `xml:"ellipse"`
+	Point      *struct{}     `xml:"point"`
+	Polygon    *tmxPoints    `xml:"polygon"`
+	Polyline   *tmxPoints    `xml:"polyline"`
+	Properties []tmxProperty `xml:"properties>property"`
+}
+
+type tmxPoints struct {
+	Points string `xml:"points,attr"`
+}
+
+// Loads a map from a TMX file. External tilesets and images are looked up
+// relative to the directory of the file.
+func LoadTMX(file string) (*Map, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadTMX(f, filepath.Dir(file))
+}
+
+// Reads a map in the TMX format. External tilesets and images are looked
+// up relative to dir.
+func ReadTMX(r io.Reader, dir string) (*Map, error) {
+	var tm tmxMap
+	if err := xml.NewDecoder(r).Decode(&tm); err != nil {
+		return nil, err
+	}
+
+	m := &Map{
+		Width:           tm.Width,
+		Height:          tm.Height,
+		TileWidth:       tm.TileWidth,
+		TileHeight:      tm.TileHeight,
+		BackgroundColor: tm.BackgroundColor,
+		Properties:      tmxProperties(tm.Properties),
+	}
+
+	for _, tts := range tm.Tilesets {
+		ts, err := tmxLoadTileset(tts, dir)
+		if err != nil {
+			return nil, err
+		}
+		m.Tilesets = append(m.Tilesets, ts)
+	}
+
+	if err := m.addTMXLayers(tm.Layers, 0, 0, 1, true); err != nil {
+		return nil, err
+	}
+
+	if err := m.check(tm.Orientation, tm.Infinite != 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Adds layers to the map, flattening groups. The offset, opacity and
+// visibility of groups apply to the layers in them.
+func (m *Map) addTMXLayers(layers []tmxLayer, offsetX, offsetY, opacity float64, visible bool) error {
+	for _, tl := range layers {
+		x, y := offsetX+tl.OffsetX, offsetY+tl.OffsetY
+		o := opacity
+		if tl.Opacity != nil {
+			o *= *tl.Opacity
+		}
+		v := visible && tmxVisible(tl.Visible)
+
+		switch tl.XMLName.Local {
+		case "layer":
+			l := &TileLayer{
+				Name:       tl.Name,
+				Visible:    v,
+				Opacity:    o,
+				OffsetX:    int(math.Floor(x + 0.5)),
+				OffsetY:    int(math.Floor(y + 0.5)),
+				Properties: tmxProperties(tl.Properties),
+			}
+
+			if tl.Data.Encoding == "" {
+				// One <tile> element per cell
+				l.Tiles = make([]uint32, len(tl.Data.Tiles))
+				for i, t := range tl.Data.Tiles {
+					l.Tiles[i] = t.GID
+				}
+			} else {
+				var err error
+				l.Tiles, err = decodeData(tl.Data.Encoding, tl.Data.Compression, tl.Data.Text)
+				if err != nil {
+					return err
+				}
+			}
+
+			m.Layers = append(m.Layers, l)
+
+		case "objectgroup":
+			g := &ObjectGroup{
+				Name:       tl.Name,
+				Visible:    v,
+				OffsetX:    int(math.Floor(x + 0.5)),
+				OffsetY:    int(math.Floor(y + 0.5)),
+				Properties: tmxProperties(tl.Properties),
+			}
+			for _, to := range tl.Objects {
+				obj, err := tmxConvertObject(to)
+				if err != nil {
+					return err
+				}
+				g.Objects = append(g.Objects, obj)
+			}
+			m.ObjectGroups = append(m.ObjectGroups, g)
+
+		case "group":
+			if err := m.addTMXLayers(tl.Layers, x, y, o, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Converts a tileset of a map, loading it from a TSX file if it is external.
+func tmxLoadTileset(tts tmxTileset, dir string) (*Tileset, error) {
+	firstGID := tts.FirstGID
+
+	if tts.Source != "" {
+		file := resolve(dir, tts.Source)
+		if strings.HasSuffix(strings.ToLower(file), ".json") {
+			ts, err := loadJSONTileset(file)
+			if err != nil {
+				return nil, err
+			}
+			ts.FirstGID = firstGID
+			return ts, nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		err = xml.NewDecoder(f).Decode(&tts)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Dir(file)
+	}
+
+	ts := &Tileset{
+		FirstGID:         firstGID,
+		Name:             tts.Name,
+		TileWidth:        tts.TileWidth,
+		TileHeight:       tts.TileHeight,
+		Spacing:          tts.Spacing,
+		Margin:           tts.Margin,
+		TileCount:        tts.TileCount,
+		Columns:          tts.Columns,
+		Image:            resolve(dir, tts.Image.Source),
+		ImageWidth:       tts.Image.Width,
+		ImageHeight:      tts.Image.Height,
+		TransparentColor: tts.Image.Trans,
+		Animations:       make(map[uint32][]Frame),
+		TileProperties:   make(map[uint32]Properties),
+	}
+
+	for _, t := range tts.Tiles {
+		if len(t.Frames) > 0 {
+			frames := make([]Frame, len(t.Frames))
+			for i, f := range t.Frames {
+				frames[i] = Frame{f.TileID, f.Duration}
+			}
+			ts.Animations[t.ID] = frames
+		}
+		if len(t.Properties) > 0 {
+			ts.TileProperties[t.ID] = tmxProperties(t.Properties)
+		}
+	}
+
+	return ts, nil
+}
+
+func tmxConvertObject(to tmxObject) (*Object, error) {
+	o := &Object{
+		ID:         to.ID,
+		Name:       to.Name,
+		Type:       to.Type,
+		X:          to.X,
+		Y:          to.Y,
+		Width:      to.Width,
+		Height:     to.Height,
+		Rotation:   to.Rotation,
+		GID:        to.GID,
+		Visible:    tmxVisible(to.Visible),
+		Ellipse:    to.Ellipse != nil,
+		Point:      to.Point != nil,
+		Properties: tmxProperties(to.Properties),
+	}
+	if o.Type == "" {
+		o.Type = to.Class
+	}
+
+	var err error
+	if to.Polygon != nil {
+		if o.Polygon, err = tmxParsePoints(to.Polygon.Points); err != nil {
+			return nil, err
+		}
+	}
+	if to.Polyline != nil {
+		if o.Polyline, err = tmxParsePoints(to.Polyline.Points); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+// Parses points stored as "x1,y1 x2,y2 ...".
+func tmxParsePoints(s string) ([]Point, error) {
+	var points []Point
+	for _, pair := range strings.Fields(s) {
+		xy := strings.Split(pair, ",")
+		if len(xy) != 2 {
+			return nil, errors.New("tilemap: invalid point \"" + pair + "\"")
+		}
+		x, err := strconv.ParseFloat(xy[0], 64)
+		if err != nil {
+			return nil, err
+		}
+		y, err := strconv.ParseFloat(xy[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, Point{x, y})
+	}
+	return points, nil
+}
+
+func tmxProperties(props []tmxProperty) Properties {
+	if len(props) == 0 {
+		return nil
+	}
+
+	p := make(Properties, len(props))
+	for _, prop := range props {
+		if prop.Value != nil {
+			p[prop.Name] = *prop.Value
+		} else {
+			p[prop.Name] = prop.Text
+		}
+	}
+	return p
+}
+
+// Layers and objects are visible unless visible="0".
+func tmxVisible(visible *int) bool {
+	return (visible == nil) || (*visible != 0)
+}
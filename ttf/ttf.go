@@ -0,0 +1,370 @@
+/*
+Package ttf provides an interface to the SDL_ttf library, which renders
+TrueType fonts into SDL surfaces.
+
+Like the core package, calls into SDL_ttf are serialized with
+sdl.GlobalMutex, and functions which create surfaces run on the Threadbound
+set with sdl.SetThreadbound.
+*/
+package ttf
+
+// #cgo CFLAGS: -D_REENTRANT
+// #cgo LDFLAGS: -lSDL_ttf -lSDL
+// #cgo windows LDFLAGS: -lwinmm -lgdi32 -ldxguid
+//
+// #include <SDL/SDL_ttf.h>
+import "C"
+import (
+	"sdl"
+	"sync"
+	"unsafe"
+)
+
+// Font styles, combined with bitwise or
+const (
+	STYLE_NORMAL        = C.TTF_STYLE_NORMAL
+	STYLE_BOLD          = C.TTF_STYLE_BOLD
+	STYLE_ITALIC        = C.TTF_STYLE_ITALIC
+	STYLE_UNDERLINE     = C.TTF_STYLE_UNDERLINE
+	STYLE_STRIKETHROUGH = C.TTF_STYLE_STRIKETHROUGH
+)
+
+// Hinting modes
+const (
+	HINTING_NORMAL = C.TTF_HINTING_NORMAL
+	HINTING_LIGHT  = C.TTF_HINTING_LIGHT
+	HINTING_MONO   = C.TTF_HINTING_MONO
+	HINTING_NONE   = C.TTF_HINTING_NONE
+)
+
+// A loaded font.
+type Font struct {
+	cfont *C.TTF_Font
+	mutex sync.RWMutex
+}
+
+func wrap(cSurface *C.SDL_Surface) *sdl.Surface {
+	return sdl.WrapSurface(unsafe.Pointer(cSurface))
+}
+
+func cColor(c sdl.Color) C.SDL_Color {
+	return C.SDL_Color{r: C.Uint8(c.R), g: C.Uint8(c.G), b: C.Uint8(c.B)}
+}
+
+// Initializes SDL_ttf.
+func Init() int {
+	sdl.GlobalMutex.Lock()
+	status := int(C.TTF_Init())
+	sdl.GlobalMutex.Unlock()
+	return status
+}
+
+// Checks to see if SDL_ttf is initialized.
+func WasInit() bool {
+	sdl.GlobalMutex.Lock()
+	status := int(C.TTF_WasInit())
+	sdl.GlobalMutex.Unlock()
+	return status != 0
+}
+
+// Shuts down SDL_ttf.
+func Quit() {
+	sdl.GlobalMutex.Lock()
+	C.TTF_Quit()
+	sdl.GlobalMutex.Unlock()
+}
+
+// Loads a font from a file at the specified point size.
+// Returns nil on error.
+func OpenFont(file string, ptsize int) *Font {
+	return OpenFontIndex(file, ptsize, 0)
+}
+
+// Loads the face with the given index from a font file (such as a .ttc
+// collection) at the specified point size. Returns nil on error.
+func OpenFontIndex(file string, ptsize int, index int) *Font {
+	cfile := C.CString(file)
+
+	sdl.GlobalMutex.Lock()
+	cfont := C.TTF_OpenFontIndex(cfile, C.int(ptsize), C.long(index))
+	sdl.GlobalMutex.Unlock()
+
+	C.free(unsafe.Pointer(cfile))
+
+	if cfont == nil {
+		return nil
+	}
+	return &Font{cfont: cfont}
+}
+
+// Frees the memory used by the font. Closing a font twice has no effect.
+// Afterwards, the methods of the font set the SDL error and return 0, false,
+// "" or nil, and -1 as status.
+func (f *Font) Close() {
+	sdl.GlobalMutex.Lock()
+	f.mutex.Lock()
+
+	if f.cfont != nil {
+		C.TTF_CloseFont(f.cfont)
+		f.cfont = nil
+	}
+
+	f.mutex.Unlock()
+	sdl.GlobalMutex.Unlock()
+}
+
+// Calls f with the C font while holding the global mutex. If the font has
+// been closed, f is not called, the SDL error is set and false is returned.
+func (f *Font) read(fn func(cfont *C.TTF_Font)) bool {
+	sdl.GlobalMutex.Lock()
+	f.mutex.RLock()
+	open := f.cfont != nil
+	if open {
+		fn(f.cfont)
+	}
+	f.mutex.RUnlock()
+	sdl.GlobalMutex.Unlock()
+	return open || closed()
+}
+
+// Calls f with the C font while holding the global mutex,
+// for functions which change the font. Returns like read.
+func (f *Font) write(fn func(cfont *C.TTF_Font)) bool {
+	sdl.GlobalMutex.Lock()
+	f.mutex.Lock()
+	open := f.cfont != nil
+	if open {
+		fn(f.cfont)
+	}
+	f.mutex.Unlock()
+	sdl.GlobalMutex.Unlock()
+	return open || closed()
+}
+
+// Sets the SDL error for using a closed font and returns false. It must
+// be called without holding the global mutex.
+func closed() bool {
+	sdl.SetError("ttf: the font has been closed")
+	return false
+}
+
+// Renders a surface with a function on the Threadbound.
+func (f *Font) render(text string, fn func(cfont *C.TTF_Font, ctext *C.char) *C.SDL_Surface) *sdl.Surface {
+	ctext := C.CString(text)
+	var surface *C.SDL_Surface
+
+	// The global mutex is taken on the Threadbound, just like event polling
+	// does, so that the two cannot deadlock
+	sdl.RunThreadbound(func() {
+		f.read(func(cfont *C.TTF_Font) {
+			surface = fn(cfont, ctext)
+		})
+	})
+
+	C.free(unsafe.Pointer(ctext))
+	return wrap(surface)
+}
+
+// Renders UTF-8 text quickly and without antialiasing into a new 8-bit
+// surface, with the colorkey set for a transparent background.
+// Returns nil on error.
+func RenderUTF8_Solid(font *Font, text string, color sdl.Color) *sdl.Surface {
+	return font.render(text, func(cfont *C.TTF_Font, ctext *C.char) *C.SDL_Surface {
+		return C.TTF_RenderUTF8_Solid(cfont, ctext, cColor(color))
+	})
+}
+
+// Renders antialiased UTF-8 text into a new 8-bit surface with the
+// background color bg. Returns nil on error.
+func RenderUTF8_Shaded(font *Font, text string, color, bg sdl.Color) *sdl.Surface {
+	return font.render(text, func(cfont *C.TTF_Font, ctext *C.char) *C.SDL_Surface {
+		return C.TTF_RenderUTF8_Shaded(cfont, ctext, cColor(color), cColor(bg))
+	})
+}
+
+// Renders antialiased UTF-8 text into a new 32-bit surface with an alpha
+// channel, for the highest quality. Returns nil on error.
+func RenderUTF8_Blended(font *Font, text string, color sdl.Color) *sdl.Surface {
+	return font.render(text, func(cfont *C.TTF_Font, ctext *C.char) *C.SDL_Surface {
+		return C.TTF_RenderUTF8_Blended(cfont, ctext, cColor(color))
+	})
+}
+
+// Returns the size of the rendered UTF-8 text, without rendering it.
+// Status is 0 if successful, or -1 on error.
+func (f *Font) SizeUTF8(text string) (w int, h int, status int) {
+	ctext := C.CString(text)
+	var cw, ch C.int
+	status = -1
+	f.read(func(cfont *C.TTF_Font) {
+		status = int(C.TTF_SizeUTF8(cfont, ctext, &cw, &ch))
+	})
+	C.free(unsafe.Pointer(ctext))
+	return int(cw), int(ch), status
+}
+
+// Returns the metrics of a glyph: its bounding box relative to the origin
+// and its advance. Status is 0 if successful, or -1 on error.
+func (f *Font) GlyphMetrics(ch uint16) (minx, maxx, miny, maxy, advance, status int) {
+	var cminx, cmaxx, cminy, cmaxy, cadvance C.int
+	status = -1
+	f.read(func(cfont *C.TTF_Font) {
+		status = int(C.TTF_GlyphMetrics(cfont, C.Uint16(ch), &cminx, &cmaxx, &cminy, &cmaxy, &cadvance))
+	})
+	return int(cminx), int(cmaxx), int(cminy), int(cmaxy), int(cadvance), status
+}
+
+// Returns true if the font has a glyph for the character.
+func (f *Font) GlyphIsProvided(ch uint16) bool {
+	var provided C.int
+	f.read(func(cfont *C.TTF_Font) {
+		provided = C.TTF_GlyphIsProvided(cfont, C.Uint16(ch))
+	})
+	return provided != 0
+}
+
+// Returns the rendering style of the font, a combination of STYLE_* values.
+func (f *Font) Style() int {
+	var style C.int
+	f.read(func(cfont *C.TTF_Font) {
+		style = C.TTF_GetFontStyle(cfont)
+	})
+	return int(style)
+}
+
+// Sets the rendering style of the font, a combination of STYLE_* values.
+func (f *Font) SetStyle(style int) {
+	f.write(func(cfont *C.TTF_Font) {
+		C.TTF_SetFontStyle(cfont, C.int(style))
+	})
+}
+
+// Returns the width of the outline of the font in pixels, 0 if there is none.
+func (f *Font) Outline() int {
+	var outline C.int
+	f.read(func(cfont *C.TTF_Font) {
+		outline = C.TTF_GetFontOutline(cfont)
+	})
+	return int(outline)
+}
+
+// Sets the width of the outline in pixels. Text is rendered as an outline
+// only if the width is greater than 0.
+func (f *Font) SetOutline(outline int) {
+	f.write(func(cfont *C.TTF_Font) {
+		C.TTF_SetFontOutline(cfont, C.int(outline))
+	})
+}
+
+// Returns the hinting mode of the font, one of the HINTING_* values.
+func (f *Font) Hinting() int {
+	var hinting C.int
+	f.read(func(cfont *C.TTF_Font) {
+		hinting = C.TTF_GetFontHinting(cfont)
+	})
+	return int(hinting)
+}
+
+// Sets the hinting mode of the font, one of the HINTING_* values.
+func (f *Font) SetHinting(hinting int) {
+	f.write(func(cfont *C.TTF_Font) {
+		C.TTF_SetFontHinting(cfont, C.int(hinting))
+	})
+}
+
+// Returns true if kerning is enabled for the font.
+func (f *Font) Kerning() bool {
+	var allowed C.int
+	f.read(func(cfont *C.TTF_Font) {
+		allowed = C.TTF_GetFontKerning(cfont)
+	})
+	return allowed != 0
+}
+
+// Enables or disables kerning for the font.
+func (f *Font) SetKerning(allowed bool) {
+	var callowed C.int
+	if allowed {
+		callowed = 1
+	}
+	f.write(func(cfont *C.TTF_Font) {
+		C.TTF_SetFontKerning(cfont, callowed)
+	})
+}
+
+// Returns the maximum height of the font in pixels.
+func (f *Font) Height() int {
+	var h C.int
+	f.read(func(cfont *C.TTF_Font) {
+		h = C.TTF_FontHeight(cfont)
+	})
+	return int(h)
+}
+
+// Returns the maximum distance from the baseline to the top of a glyph.
+func (f *Font) Ascent() int {
+	var a C.int
+	f.read(func(cfont *C.TTF_Font) {
+		a = C.TTF_FontAscent(cfont)
+	})
+	return int(a)
+}
+
+// Returns the maximum distance from the baseline to the bottom of a glyph,
+// as a negative number.
+func (f *Font) Descent() int {
+	var d C.int
+	f.read(func(cfont *C.TTF_Font) {
+		d = C.TTF_FontDescent(cfont)
+	})
+	return int(d)
+}
+
+// Returns the recommended distance between two lines of text.
+func (f *Font) LineSkip() int {
+	var skip C.int
+	f.read(func(cfont *C.TTF_Font) {
+		skip = C.TTF_FontLineSkip(cfont)
+	})
+	return int(skip)
+}
+
+// Returns the number of faces available in the font file.
+func (f *Font) Faces() int {
+	var faces C.long
+	f.read(func(cfont *C.TTF_Font) {
+		faces = C.TTF_FontFaces(cfont)
+	})
+	return int(faces)
+}
+
+// Returns true if all glyphs of the face have the same width.
+func (f *Font) FaceIsFixedWidth() bool {
+	var fixed C.int
+	f.read(func(cfont *C.TTF_Font) {
+		fixed = C.TTF_FontFaceIsFixedWidth(cfont)
+	})
+	return fixed != 0
+}
+
+// Returns the family name of the face, such as "Times", or "" if unknown.
+func (f *Font) FaceFamilyName() string {
+	var name string
+	f.read(func(cfont *C.TTF_Font) {
+		if cname := C.TTF_FontFaceFamilyName(cfont); cname != nil {
+			name = C.GoString(cname)
+		}
+	})
+	return name
+}
+
+// Returns the style name of the face, such as "Bold", or "" if unknown.
+func (f *Font) FaceStyleName() string {
+	var name string
+	f.read(func(cfont *C.TTF_Font) {
+		if cname := C.TTF_FontFaceStyleName(cfont); cname != nil {
+			name = C.GoString(cname)
+		}
+	})
+	return name
+}
@@ -26,7 +26,15 @@ func SetVideoMode(w int, h int, bpp int, flags uint32) *Surface {
 
 func setVideoMode(w int, h int, bpp int, flags uint32) *Surface {
 	screen := C.SDL_SetVideoMode(C.int(w), C.int(h), C.int(bpp), C.Uint32(flags))
-	currentVideoSurface = wrap(screen)
+	if screen == nil {
+		currentVideoSurface = nil
+		return nil
+	}
+
+	// The video surface is owned by SDL, so unlike wrap, this does not track it
+	var surface Surface
+	surface.setCSurface(unsafe.Pointer(screen))
+	currentVideoSurface = &surface
 	return currentVideoSurface
 }
 
@@ -52,7 +60,19 @@ func VideoModeOK(width int, height int, bpp int, flags uint32) int {
 // NOTE: The result of this function uses a different encoding than the underlying C function.
 // It returns an empty array if no modes are available,
 // and nil if any dimension is okay for the given format.
+// See DisplayModes for a clearer interface.
 func ListModes(format *PixelFormat, flags uint32) []Rect {
+	var ret []Rect
+	thread.Run(func() {
+		GlobalMutex.Lock()
+		ret = listModes(format, flags)
+		GlobalMutex.Unlock()
+	})
+	return ret
+}
+
+// Copies the list of modes while SDL cannot change it.
+func listModes(format *PixelFormat, flags uint32) []Rect {
 	modes := C.SDL_ListModes((*C.SDL_PixelFormat)(unsafe.Pointer(format)), C.Uint32(flags))
 
 	// No modes available
@@ -101,10 +121,24 @@ type VideoInfo struct {
 	Current_h    int32        "Value: The current video mode height"
 }
 
+// Returns information about the video hardware, or nil if the video
+// subsystem has not been initialized.
 func GetVideoInfo() *VideoInfo {
-	//GlobalMutex.Lock()
+	var info *VideoInfo
+	thread.Run(func() {
+		GlobalMutex.Lock()
+		info = getVideoInfo()
+		GlobalMutex.Unlock()
+	})
+	return info
+}
+
+// Copies the video info while SDL cannot change it.
+func getVideoInfo() *VideoInfo {
 	vinfo := (*internalVideoInfo)(unsafe.Pointer(C.SDL_GetVideoInfo()))
-	//GlobalMutex.Unlock()
+	if vinfo == nil {
+		return nil
+	}
 
 	flags := vinfo.Flags
 
@@ -131,7 +165,9 @@ func (screen *Surface) UpdateRect(x int32, y int32, w uint32, h uint32) {
 	//GlobalMutex.Lock()
 	screen.mutex.Lock()
 
-	C.SDL_UpdateRect(screen.cSurface, C.Sint32(x), C.Sint32(y), C.Uint32(w), C.Uint32(h))
+	if !screen.freed("Surface.UpdateRect") {
+		C.SDL_UpdateRect(screen.cSurface, C.Sint32(x), C.Sint32(y), C.Uint32(w), C.Uint32(h))
+	}
 
 	screen.mutex.Unlock()
 	//GlobalMutex.Unlock()
@@ -142,7 +178,9 @@ func (screen *Surface) UpdateRects(rects []Rect) {
 		//GlobalMutex.Lock()
 		screen.mutex.Lock()
 
-		C.SDL_UpdateRects(screen.cSurface, C.int(len(rects)), (*C.SDL_Rect)(unsafe.Pointer(&rects[0])))
+		if !screen.freed("Surface.UpdateRects") {
+			C.SDL_UpdateRects(screen.cSurface, C.int(len(rects)), (*C.SDL_Rect)(unsafe.Pointer(&rects[0])))
+		}
 
 		screen.mutex.Unlock()
 		//GlobalMutex.Unlock()
@@ -209,7 +247,10 @@ func GL_SwapBuffers() {
 	})
 }
 
-func GL_SetAttribute(attr int, value int) int {
+// Sets an OpenGL attribute, which takes effect on the next call to
+// SetVideoMode with the OPENGL flag. Returns 0 if successful or -1 if there
+// was an error.
+func GL_SetAttribute(attr GLAttr, value int) int {
 	var status int
 	thread.Run(func() {
 		status = int(C.SDL_GL_SetAttribute(C.SDL_GLattr(attr), C.int(value)))
@@ -217,12 +258,59 @@ func GL_SetAttribute(attr int, value int) int {
 	return status
 }
 
+// Stores the actual value of an OpenGL attribute of the current OpenGL
+// display in value, which may differ from the requested value.
+// Returns 0 if successful or -1 if there was an error.
+func GL_GetAttribute(attr GLAttr, value *int) int {
+	var status int
+	var cvalue C.int
+	thread.Run(func() {
+		status = int(C.SDL_GL_GetAttribute(C.SDL_GLattr(attr), &cvalue))
+	})
+	*value = int(cvalue)
+	return status
+}
+
+// Loads the OpenGL library from the given path, or the default library if
+// path is "". Must be called before SetVideoMode. Returns 0 if successful
+// or -1 if there was an error.
+func GL_LoadLibrary(path string) int {
+	var cpath *C.char
+	if path != "" {
+		cpath = C.CString(path)
+		defer C.free(unsafe.Pointer(cpath))
+	}
+
+	var status int
+	thread.Run(func() {
+		status = int(C.SDL_GL_LoadLibrary(cpath))
+	})
+	return status
+}
+
+// Returns the address of an OpenGL function, such as "glClear", or nil if
+// it is not available. This is meant for OpenGL loaders. Only valid after
+// an OpenGL display has been created.
+func GL_GetProcAddress(proc string) unsafe.Pointer {
+	cproc := C.CString(proc)
+	defer C.free(unsafe.Pointer(cproc))
+
+	var addr unsafe.Pointer
+	thread.Run(func() {
+		addr = C.SDL_GL_GetProcAddress(cproc)
+	})
+	return addr
+}
+
 // Swaps screen buffers.
 func (screen *Surface) Flip() int {
 	//GlobalMutex.Lock()
 	screen.mutex.Lock()
 
-	status := int(C.SDL_Flip(screen.cSurface))
+	status := -1
+	if !screen.freed("Surface.Flip") {
+		status = int(C.SDL_Flip(screen.cSurface))
+	}
 
 	screen.mutex.Unlock()
 	//GlobalMutex.Unlock()
@@ -230,14 +318,17 @@ func (screen *Surface) Flip() int {
 	return status
 }
 
-// Frees (deletes) a Surface
+// Frees (deletes) a Surface. Freeing a surface twice has no effect; other
+// methods of a freed surface fail with an error.
 func (screen *Surface) Free() {
 	//GlobalMutex.Lock()
 	screen.mutex.Lock()
 
-	C.SDL_FreeSurface(screen.cSurface)
-
-	screen.destroy()
+	if screen.cSurface != nil {
+		untrack(screen)
+		C.SDL_FreeSurface(screen.cSurface)
+		screen.destroy()
+	}
 	if screen == currentVideoSurface {
 		currentVideoSurface = nil
 	}
@@ -247,17 +338,30 @@ func (screen *Surface) Free() {
 }
 
 // Locks a surface for direct access.
+//
+// Until Unlock is called, the surface is also protected against concurrent
+// use by other goroutines, and methods such as Blit or FillRect must not be
+// called on it. Locks cannot be nested.
 func (screen *Surface) Lock() int {
 	screen.mutex.Lock()
-	status := int(C.SDL_LockSurface(screen.cSurface))
-	screen.mutex.Unlock()
+	status := -1
+	if !screen.freed("Surface.Lock") {
+		status = int(C.SDL_LockSurface(screen.cSurface))
+	}
+	if status != 0 {
+		screen.mutex.Unlock()
+		return status
+	}
+	screen.reloadPixels()
 	return status
 }
 
 // Unlocks a previously locked surface.
 func (screen *Surface) Unlock() {
-	screen.mutex.Lock()
-	C.SDL_UnlockSurface(screen.cSurface)
+	if screen.cSurface != nil {
+		C.SDL_UnlockSurface(screen.cSurface)
+		screen.reloadPixels()
+	}
 	screen.mutex.Unlock()
 }
 
@@ -279,11 +383,25 @@ func (dst *Surface) Blit(dstrect *Rect, src *Surface, srcrect *Rect) int {
 		src.mutex.RLock()
 		dst.mutex.Lock()
 
-		ret = C.SDL_UpperBlit(
-			src.cSurface,
-			(*C.SDL_Rect)(unsafe.Pointer(srcrect)),
-			dst.cSurface,
-			(*C.SDL_Rect)(unsafe.Pointer(dstrect)))
+		if src.freed("Surface.Blit") || dst.freed("Surface.Blit") {
+			ret = -1
+		} else {
+			// SDL stores the clipped destination area in dstrect
+			var clipped Rect
+			if (dstrect == nil) && (dst.dirty != nil) {
+				dstrect = &clipped
+			}
+
+			ret = C.SDL_UpperBlit(
+				src.cSurface,
+				(*C.SDL_Rect)(unsafe.Pointer(srcrect)),
+				dst.cSurface,
+				(*C.SDL_Rect)(unsafe.Pointer(dstrect)))
+
+			if (ret == 0) && (dst.dirty != nil) {
+				dst.dirty.Add(*dstrect)
+			}
+		}
 
 		dst.mutex.Unlock()
 		src.mutex.RUnlock()
@@ -305,29 +423,57 @@ func BlitSurface(src *Surface, srcrect *Rect, dst *Surface, dstrect *Rect) int {
 func (dst *Surface) FillRect(dstrect *Rect, color uint32) int {
 	dst.mutex.Lock()
 
-	var ret = C.SDL_FillRect(
-		dst.cSurface,
-		(*C.SDL_Rect)(unsafe.Pointer(dstrect)),
-		C.Uint32(color))
+	var ret C.int = -1
+	if !dst.freed("Surface.FillRect") {
+		// SDL stores the clipped area in dstrect
+		var clipped Rect
+		if (dstrect == nil) && (dst.dirty != nil) {
+			clipped = *(*Rect)(unsafe.Pointer(&dst.cSurface.clip_rect))
+			dstrect = &clipped
+		}
+
+		ret = C.SDL_FillRect(
+			dst.cSurface,
+			(*C.SDL_Rect)(unsafe.Pointer(dstrect)),
+			C.Uint32(color))
+
+		if (ret == 0) && (dst.dirty != nil) {
+			dst.dirty.Add(*dstrect)
+		}
+	}
 
 	dst.mutex.Unlock()
 
 	return int(ret)
 }
 
-// Adjusts the alpha properties of a Surface.
+// Adjusts the alpha properties of a Surface. If successful, Flags is
+// reloaded, so that it shows whether SRCALPHA is set.
 func (s *Surface) SetAlpha(flags uint32, alpha uint8) int {
 	s.mutex.Lock()
-	status := int(C.SDL_SetAlpha(s.cSurface, C.Uint32(flags), C.Uint8(alpha)))
+	status := -1
+	if !s.freed("Surface.SetAlpha") {
+		status = int(C.SDL_SetAlpha(s.cSurface, C.Uint32(flags), C.Uint8(alpha)))
+		if status == 0 {
+			s.reload()
+		}
+	}
 	s.mutex.Unlock()
 	return status
 }
 
 // Sets the color key (transparent pixel)  in  a  blittable  surface  and
-// enables or disables RLE blit acceleration.
+// enables or disables RLE blit acceleration. If successful, Flags is
+// reloaded, so that it shows whether SRCCOLORKEY is set.
 func (s *Surface) SetColorKey(flags uint32, ColorKey uint32) int {
 	s.mutex.Lock()
-	status := int(C.SDL_SetColorKey(s.cSurface, C.Uint32(flags), C.Uint32(ColorKey)))
+	status := -1
+	if !s.freed("Surface.SetColorKey") {
+		status = int(C.SDL_SetColorKey(s.cSurface, C.Uint32(flags), C.Uint32(ColorKey)))
+		if status == 0 {
+			s.reload()
+		}
+	}
 	s.mutex.Unlock()
 	return status
 }
@@ -335,14 +481,18 @@ func (s *Surface) SetColorKey(flags uint32, ColorKey uint32) int {
 // Gets the clipping rectangle for a surface.
 func (s *Surface) GetClipRect(r *Rect) {
 	s.mutex.RLock()
-	C.SDL_GetClipRect(s.cSurface, (*C.SDL_Rect)(unsafe.Pointer(r)))
+	if !s.freed("Surface.GetClipRect") {
+		C.SDL_GetClipRect(s.cSurface, (*C.SDL_Rect)(unsafe.Pointer(r)))
+	}
 	s.mutex.RUnlock()
 }
 
 // Sets the clipping rectangle for a surface.
 func (s *Surface) SetClipRect(r *Rect) {
 	s.mutex.Lock()
-	C.SDL_SetClipRect(s.cSurface, (*C.SDL_Rect)(unsafe.Pointer(r)))
+	if !s.freed("Surface.SetClipRect") {
+		C.SDL_SetClipRect(s.cSurface, (*C.SDL_Rect)(unsafe.Pointer(r)))
+	}
 	s.mutex.Unlock()
 }
 
@@ -385,14 +535,19 @@ func CreateRGBSurfaceFrom(pixels interface{}, width, height, bpp, pitch int, Rma
 	//GlobalMutex.Unlock()
 
 	s := wrap(p)
-	s.gcPixels = pixels
+	if s != nil {
+		s.gcPixels = pixels
+	}
 	return s
 }
 
 // Converts a surface to the display format
 func (s *Surface) DisplayFormat() *Surface {
 	s.mutex.RLock()
-	p := C.SDL_DisplayFormat(s.cSurface)
+	var p *C.SDL_Surface
+	if !s.freed("Surface.DisplayFormat") {
+		p = C.SDL_DisplayFormat(s.cSurface)
+	}
 	s.mutex.RUnlock()
 	return wrap(p)
 }
@@ -400,7 +555,10 @@ func (s *Surface) DisplayFormat() *Surface {
 // Converts a surface to the display format with alpha
 func (s *Surface) DisplayFormatAlpha() *Surface {
 	s.mutex.RLock()
-	p := C.SDL_DisplayFormatAlpha(s.cSurface)
+	var p *C.SDL_Surface
+	if !s.freed("Surface.DisplayFormatAlpha") {
+		p = C.SDL_DisplayFormatAlpha(s.cSurface)
+	}
 	s.mutex.RUnlock()
 	return wrap(p)
 }
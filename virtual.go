@@ -0,0 +1,293 @@
+package sdl
+
+// How a VirtualScreen is scaled to the video surface.
+type Scaling int
+
+const (
+	// Scales by the largest whole factor which fits, so that every logical
+	// pixel has the same size. If the video surface is smaller than the
+	// logical screen, AspectScaling is used instead.
+	IntegerScaling Scaling = iota
+
+	// Scales as large as possible, keeping the aspect ratio.
+	AspectScaling
+
+	// Scales to the whole video surface, ignoring the aspect ratio.
+	StretchScaling
+)
+
+// A screen with a fixed logical resolution, which is drawn onto Surface
+// and scaled onto the video surface by Flip. The area of the video surface
+// which is not covered is filled with BorderColor.
+//
+// Scaling uses the nearest pixel, so that pixels stay crisp. OPENGL video
+// surfaces are not supported.
+type VirtualScreen struct {
+	Surface     *Surface // The logical screen, to be drawn onto
+	Scaling     Scaling
+	BorderColor uint32 // In the format of the video surface
+
+	screen    *Surface
+	converted *Surface // Surface in the format of the video surface, if they differ
+	columns   []int    // The byte offsets of the source pixels of a scaled row
+	columnsOf [2]int   // The source width and bytes per pixel columns was made for
+
+	// The parts of relative mouse motion which were too small to move by
+	// a logical pixel, in video surface pixels times logical pixels
+	restX, restY int
+}
+
+// The flags of SetVideoMode which are kept when the video mode is changed
+// because of a ResizeEvent.
+const videoModeFlags = SWSURFACE | HWSURFACE | ASYNCBLIT | ANYFORMAT | HWPALETTE | DOUBLEBUF | FULLSCREEN | RESIZABLE | NOFRAME
+
+// Creates a logical screen of size w, h in the format of the video surface,
+// which is shown on the video surface screen.
+// Returns nil on error.
+func NewVirtualScreen(w, h int, screen *Surface, scaling Scaling) *VirtualScreen {
+	f := screen.Format
+	if f == nil {
+		SetError("NewVirtualScreen: the surface has been freed")
+		return nil
+	}
+
+	s := CreateRGBSurface(SWSURFACE, w, h, int(f.BitsPerPixel), f.Rmask, f.Gmask, f.Bmask, f.Amask)
+	if s == nil {
+		return nil
+	}
+	if colors := screen.Palette(); colors != nil {
+		s.SetColors(colors, 0)
+	}
+
+	return &VirtualScreen{
+		Surface: s,
+		Scaling: scaling,
+		screen:  screen,
+	}
+}
+
+// Returns the video surface, which changes when HandleEvent handles
+// a ResizeEvent.
+func (v *VirtualScreen) Screen() *Surface {
+	return v.screen
+}
+
+// Sets the video surface, for example after changing the video mode.
+func (v *VirtualScreen) SetScreen(screen *Surface) {
+	v.screen = screen
+	v.restX, v.restY = 0, 0
+}
+
+// Returns the area of the video surface which shows the logical screen.
+func (v *VirtualScreen) Viewport() Rect {
+	sw, sh := int(v.screen.W), int(v.screen.H)
+	w, h := int(v.Surface.W), int(v.Surface.H)
+	if (w <= 0) || (h <= 0) || (sw <= 0) || (sh <= 0) {
+		return Rect{}
+	}
+
+	dw, dh := sw, sh
+	switch v.Scaling {
+	case IntegerScaling:
+		if n := minInt(sw/w, sh/h); n >= 1 {
+			dw, dh = n*w, n*h
+			break
+		}
+		fallthrough
+	case AspectScaling:
+		if sw*h <= sh*w {
+			dw, dh = sw, maxInt(h*sw/w, 1)
+		} else {
+			dw, dh = maxInt(w*sh/h, 1), sh
+		}
+	}
+
+	return Rect{X: int16((sw - dw) / 2), Y: int16((sh - dh) / 2), W: uint16(dw), H: uint16(dh)}
+}
+
+// Converts a position on the video surface, for example of the mouse, to
+// the logical screen. Positions outside of the logical screen are clamped
+// to its edges, and inside is false.
+func (v *VirtualScreen) ToLogical(x, y int) (lx, ly int, inside bool) {
+	r := v.Viewport()
+	if r.Empty() {
+		return 0, 0, false
+	}
+
+	inside = r.Contains(x, y)
+	x = clamp(x-int(r.X), 0, int(r.W)-1)
+	y = clamp(y-int(r.Y), 0, int(r.H)-1)
+	return x * int(v.Surface.W) / int(r.W), y * int(v.Surface.H) / int(r.H), inside
+}
+
+// Adapts an event received from Events to the logical screen: the positions
+// of mouse events are converted to the logical screen, and on a ResizeEvent
+// the video mode is set to the new size. Other events are returned as they
+// are.
+//
+// Setting the video mode replaces the video surface, so after a ResizeEvent
+// the surface which was passed to NewVirtualScreen must not be used anymore;
+// callers which draw onto the video surface directly must get it from Screen.
+func (v *VirtualScreen) HandleEvent(event interface{}) interface{} {
+	switch e := event.(type) {
+	case MouseMotionEvent:
+		x, y, _ := v.ToLogical(int(e.X), int(e.Y))
+		if r := v.Viewport(); !r.Empty() {
+			e.Xrel = int16(scaleMotion(int(e.Xrel), int(v.Surface.W), int(r.W), &v.restX))
+			e.Yrel = int16(scaleMotion(int(e.Yrel), int(v.Surface.H), int(r.H), &v.restY))
+		}
+		e.X, e.Y = uint16(x), uint16(y)
+		return e
+
+	case MouseButtonEvent:
+		x, y, _ := v.ToLogical(int(e.X), int(e.Y))
+		e.X, e.Y = uint16(x), uint16(y)
+		return e
+
+	case ResizeEvent:
+		if v.screen.Format != nil {
+			bpp := int(v.screen.Format.BitsPerPixel)
+			if screen := SetVideoMode(int(e.W), int(e.H), bpp, v.screen.Flags&videoModeFlags); screen != nil {
+				v.screen = screen
+				// The viewport has changed, so the remainders do not apply
+				v.restX, v.restY = 0, 0
+			}
+		}
+	}
+	return event
+}
+
+// Scales a relative motion by logical/size, keeping the remainder in rest
+// for the next motion so that slow motions are not lost.
+func scaleMotion(rel, logical, size int, rest *int) int {
+	n := rel*logical + *rest
+	scaled := n / size
+	*rest = n - scaled*size
+	return scaled
+}
+
+// Scales the logical screen onto the video surface, fills the borders and
+// flips the video surface.
+// Returns 0 if successful or -1 on error.
+func (v *VirtualScreen) Flip() int {
+	screen := v.screen
+	r := v.Viewport()
+	if r.Empty() {
+		return screen.Flip()
+	}
+
+	src := v.Surface
+	if (screen.Format != nil) && (src.Format != nil) && !src.Format.Equal(screen.Format) {
+		if src = v.convert(); src == nil {
+			return -1
+		}
+	}
+
+	// The borders
+	sw, sh := screen.W, screen.H
+	bottom, right := int32(r.Y)+int32(r.H), int32(r.X)+int32(r.W)
+	borders := []Rect{
+		{0, 0, uint16(sw), uint16(r.Y)},
+		{0, int16(bottom), uint16(sw), uint16(sh - bottom)},
+		{0, r.Y, uint16(r.X), r.H},
+		{int16(right), r.Y, uint16(sw - right), r.H},
+	}
+	for _, b := range borders {
+		if !b.Empty() && (screen.FillRect(&b, v.BorderColor) != 0) {
+			return -1
+		}
+	}
+
+	if (int(r.W) == int(src.W)) && (int(r.H) == int(src.H)) {
+		if screen.Blit(&Rect{X: r.X, Y: r.Y}, src, nil) != 0 {
+			return -1
+		}
+	} else {
+		if screen.Lock() != 0 {
+			return -1
+		}
+		if src.Lock() != 0 {
+			screen.Unlock()
+			return -1
+		}
+		v.scale(screen, r, src)
+		src.Unlock()
+		screen.Unlock()
+	}
+
+	return screen.Flip()
+}
+
+// Returns the logical screen converted to the format of the video surface.
+// Returns nil on error.
+func (v *VirtualScreen) convert() *Surface {
+	c, f := v.converted, v.screen.Format
+	if (c == nil) || (c.W != v.Surface.W) || (c.H != v.Surface.H) || !c.Format.Equal(f) {
+		if c != nil {
+			c.Free()
+		}
+		c = CreateRGBSurface(SWSURFACE, int(v.Surface.W), int(v.Surface.H), int(f.BitsPerPixel), f.Rmask, f.Gmask, f.Bmask, f.Amask)
+		if c == nil {
+			v.converted = nil
+			return nil
+		}
+		if colors := v.screen.Palette(); colors != nil {
+			c.SetColors(colors, 0)
+		}
+		v.converted = c
+	}
+
+	if c.Blit(nil, v.Surface, nil) != 0 {
+		return nil
+	}
+	return c
+}
+
+// Scales src onto the area r of dst, which has the same format, using the
+// nearest pixels. Both surfaces must be locked.
+func (v *VirtualScreen) scale(dst *Surface, r Rect, src *Surface) {
+	bpp := int(src.Format.BytesPerPixel)
+	spix, spitch := src.pixelBytes()
+	dpix, dpitch := dst.pixelBytes()
+	if (spix == nil) || (dpix == nil) {
+		return
+	}
+	sw, sh := int(src.W), int(src.H)
+	dw, dh := int(r.W), int(r.H)
+
+	if (len(v.columns) != dw) || (v.columnsOf != [2]int{sw, bpp}) {
+		v.columns = make([]int, dw)
+		for x := range v.columns {
+			v.columns[x] = (x * sw / dw) * bpp
+		}
+		v.columnsOf = [2]int{sw, bpp}
+	}
+
+	var previous []byte
+	previousY := -1
+	for y := 0; y < dh; y++ {
+		start := (int(r.Y)+y)*dpitch + int(r.X)*bpp
+		row := dpix[start : start+dw*bpp]
+
+		sy := y * sh / dh
+		if sy == previousY {
+			copy(row, previous)
+			continue
+		}
+
+		srow := spix[sy*spitch:]
+		for x, offset := range v.columns {
+			copy(row[x*bpp:x*bpp+bpp], srow[offset:offset+bpp])
+		}
+		previous, previousY = row, sy
+	}
+}
+
+// Frees the logical screen. The video surface is not freed.
+func (v *VirtualScreen) Free() {
+	v.Surface.Free()
+	if v.converted != nil {
+		v.converted.Free()
+		v.converted = nil
+	}
+}
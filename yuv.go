@@ -0,0 +1,171 @@
+package sdl
+
+import "image"
+
+// Converts an RGB color to Y, U (Cb) and V (Cr) values, using the ITU-R
+// BT.601 coefficients and the studio range (16-235 for Y, 16-240 for U
+// and V) expected by video overlays.
+func RGBToYUV(r, g, b uint8) (y, u, v uint8) {
+	R, G, B := int32(r), int32(g), int32(b)
+	y = uint8((66*R+129*G+25*B+128)>>8 + 16)
+	u = uint8((-38*R-74*G+112*B+128)>>8 + 128)
+	v = uint8((112*R-94*G-18*B+128)>>8 + 128)
+	return
+}
+
+// Returns the color of a pixel as 8-bit RGB, composited over black.
+func rgbAt(img image.Image, x, y int) (r, g, b int32) {
+	if rgba, ok := img.(*image.RGBA); ok {
+		p := rgba.Pix[rgba.PixOffset(x, y):]
+		return int32(p[0]), int32(p[1]), int32(p[2])
+	}
+	R, G, B, _ := img.At(x, y).RGBA()
+	return int32(R >> 8), int32(G >> 8), int32(B >> 8)
+}
+
+// Converts the image to YUV and stores it in the overlay, locking the
+// overlay while it is written. The top-left corner of the image is stored
+// at the top-left corner of the overlay; the parts of the image outside
+// the overlay are ignored. Returns 0 if successful or -1 if there was an
+// error.
+//
+// The chroma of each 2x2 block (planar formats) or pair of pixels (packed
+// formats) is averaged. *image.YCbCr images with 4:2:0 subsampling, as
+// produced by video decoders, are copied into planar overlays unchanged.
+func (o *Overlay) SetImage(img image.Image) int {
+	if o.freed("Overlay.SetImage") {
+		return -1
+	}
+
+	switch o.Format {
+	case YV12_OVERLAY, IYUV_OVERLAY, YUY2_OVERLAY, UYVY_OVERLAY, YVYU_OVERLAY:
+	default:
+		SetError("Overlay.SetImage: unsupported overlay format")
+		return -1
+	}
+
+	if o.Lock() != 0 {
+		return -1
+	}
+	defer o.Unlock()
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > int(o.W) {
+		w = int(o.W)
+	}
+	if h > int(o.H) {
+		h = int(o.H)
+	}
+
+	if o.planar() {
+		if ycbcr, ok := img.(*image.YCbCr); ok && (ycbcr.SubsampleRatio == image.YCbCrSubsampleRatio420) {
+			o.copyYCbCr(ycbcr, w, h)
+		} else {
+			o.setPlanar(img, w, h)
+		}
+	} else {
+		o.setPacked(img, w, h)
+	}
+
+	return 0
+}
+
+// Returns the Y, U and V planes of a planar overlay.
+func (o *Overlay) yuvPlanes() (yp, up, vp []byte) {
+	if o.Format == YV12_OVERLAY {
+		return o.Plane(0), o.Plane(2), o.Plane(1)
+	}
+	return o.Plane(0), o.Plane(1), o.Plane(2)
+}
+
+// Fills a planar overlay from w x h pixels of an RGB image.
+func (o *Overlay) setPlanar(img image.Image, w, h int) {
+	yp, up, vp := o.yuvPlanes()
+	ypitch, cpitch := o.Pitch(0), o.Pitch(1)
+	min := img.Bounds().Min
+
+	for y := 0; y < h; y += 2 {
+		for x := 0; x < w; x += 2 {
+			// Sum of the colors of the 2x2 block, which is cut off at the
+			// right and bottom edge for odd sizes
+			var sumR, sumG, sumB, n int32
+			for dy := 0; (dy < 2) && (y+dy < h); dy++ {
+				for dx := 0; (dx < 2) && (x+dx < w); dx++ {
+					r, g, b := rgbAt(img, min.X+x+dx, min.Y+y+dy)
+					Y, _, _ := RGBToYUV(uint8(r), uint8(g), uint8(b))
+					yp[(y+dy)*ypitch+x+dx] = Y
+					sumR, sumG, sumB, n = sumR+r, sumG+g, sumB+b, n+1
+				}
+			}
+
+			i := (y/2)*cpitch + x/2
+			if i >= len(up) {
+				// The chroma planes have no row for the last odd row
+				continue
+			}
+			_, U, V := RGBToYUV(uint8(sumR/n), uint8(sumG/n), uint8(sumB/n))
+			up[i], vp[i] = U, V
+		}
+	}
+}
+
+// Copies w x h pixels of a 4:2:0 image into a planar overlay.
+func (o *Overlay) copyYCbCr(img *image.YCbCr, w, h int) {
+	yp, up, vp := o.yuvPlanes()
+	ypitch, cpitch := o.Pitch(0), o.Pitch(1)
+	min := img.Rect.Min
+
+	for y := 0; y < h; y++ {
+		yi := img.YOffset(min.X, min.Y+y)
+		copy(yp[y*ypitch:y*ypitch+w], img.Y[yi:yi+w])
+	}
+
+	cw := (w + 1) / 2
+	if cw > cpitch {
+		cw = cpitch
+	}
+	for y := 0; (y < h) && ((y/2)*cpitch+cw <= len(up)); y += 2 {
+		ci := img.COffset(min.X, min.Y+y)
+		n := cw
+		if n > len(img.Cb)-ci {
+			n = len(img.Cb) - ci
+		}
+		copy(up[(y/2)*cpitch:(y/2)*cpitch+n], img.Cb[ci:ci+n])
+		copy(vp[(y/2)*cpitch:(y/2)*cpitch+n], img.Cr[ci:ci+n])
+	}
+}
+
+// Fills a packed overlay from w x h pixels of an RGB image.
+func (o *Overlay) setPacked(img image.Image, w, h int) {
+	pix := o.Plane(0)
+	pitch := o.Pitch(0)
+	min := img.Bounds().Min
+
+	// Byte offsets of Y0, U, Y1 and V within a pair of pixels
+	var y0, u, y1, v int
+	switch o.Format {
+	case YUY2_OVERLAY:
+		y0, u, y1, v = 0, 1, 2, 3
+	case UYVY_OVERLAY:
+		u, y0, v, y1 = 0, 1, 2, 3
+	case YVYU_OVERLAY:
+		y0, v, y1, u = 0, 1, 2, 3
+	}
+
+	for y := 0; y < h; y++ {
+		row := pix[y*pitch:]
+		for x := 0; (x < w) && (x*2+4 <= len(row)); x += 2 {
+			r0, g0, b0 := rgbAt(img, min.X+x, min.Y+y)
+			r1, g1, b1 := r0, g0, b0
+			if x+1 < w {
+				r1, g1, b1 = rgbAt(img, min.X+x+1, min.Y+y)
+			}
+
+			p := row[x*2 : x*2+4]
+			p[y0], _, _ = RGBToYUV(uint8(r0), uint8(g0), uint8(b0))
+			p[y1], _, _ = RGBToYUV(uint8(r1), uint8(g1), uint8(b1))
+			_, p[u], p[v] = RGBToYUV(uint8((r0+r1)/2), uint8((g0+g1)/2), uint8((b0+b1)/2))
+		}
+	}
+}